@@ -0,0 +1,49 @@
+package cloudapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// auditStore records the machine actions this double has served, keyed by
+// machine id.
+type auditStore struct {
+	mu      sync.Mutex
+	entries map[string][]*cloudapi.AuditAction
+}
+
+// recordMachineAudit appends an entry to machineID's audit trail for an
+// action dispatched via POST /:account/machines/:id?action=.... success is
+// reported as "yes"/"no", matching the real CloudAPI's AuditAction.Success.
+func (c *CloudAPI) recordMachineAudit(machineID, action string, success bool) {
+	outcome := "yes"
+	if !success {
+		outcome = "no"
+	}
+
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	if c.audit.entries == nil {
+		c.audit.entries = map[string][]*cloudapi.AuditAction{}
+	}
+	c.audit.entries[machineID] = append(c.audit.entries[machineID], &cloudapi.AuditAction{
+		Action:  action,
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Success: outcome,
+	})
+}
+
+// MachineAudit returns machineID's recorded action history, most recent
+// first, the way CloudAPI does.
+func (c *CloudAPI) MachineAudit(machineID string) ([]*cloudapi.AuditAction, error) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	entries := c.audit.entries[machineID]
+	history := make([]*cloudapi.AuditAction, len(entries))
+	for i, entry := range entries {
+		history[len(entries)-1-i] = entry
+	}
+	return history, nil
+}
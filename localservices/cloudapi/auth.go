@@ -0,0 +1,238 @@
+package cloudapi
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"golang.org/x/crypto/ssh"
+)
+
+// defaultClockSkew is the maximum allowed drift between a signed request's
+// Date header and the server's clock when RequireHTTPSignatures was enabled
+// with a zero clockSkew.
+const defaultClockSkew = 5 * time.Minute
+
+// httpSignature is the parsed content of an
+// `Authorization: Signature ...` header.
+type httpSignature struct {
+	keyID     string
+	algorithm string
+	headers   []string
+	signature []byte
+}
+
+// verifyRequestSignature authenticates r using the Joyent HTTP Signature
+// scheme: it parses the Authorization header, rebuilds the canonical
+// signing string from the listed headers, looks up the account's key by
+// keyId and checks the signature against it.
+func (c *CloudAPI) verifyRequestSignature(r *http.Request) error {
+	if err := c.checkDateSkew(r); err != nil {
+		return err
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return errInvalidCredentials("missing Authorization header")
+	}
+	sig, err := parseAuthorizationHeader(header)
+	if err != nil {
+		return errInvalidCredentials(err.Error())
+	}
+
+	key, err := c.findSigningKey(sig.keyID)
+	if err != nil {
+		return errInvalidCredentials("unknown key: " + sig.keyID)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.Key))
+	if err != nil {
+		return errInvalidCredentials("malformed public key for " + sig.keyID)
+	}
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return errInvalidCredentials("unsupported key type for " + sig.keyID)
+	}
+
+	signingString, err := buildSigningString(r, sig.headers)
+	if err != nil {
+		return errInvalidCredentials(err.Error())
+	}
+	if err := verifySignatureBytes(cryptoPub.CryptoPublicKey(), sig.algorithm, signingString, sig.signature); err != nil {
+		return errInvalidCredentials("signature verification failed")
+	}
+	return nil
+}
+
+// checkDateSkew rejects requests whose Date header has drifted too far from
+// now, the same replay protection real CloudAPI applies.
+func (c *CloudAPI) checkDateSkew(r *http.Request) error {
+	dateHeader := r.Header.Get("Date")
+	if dateHeader == "" {
+		return errInvalidCredentials("missing Date header")
+	}
+	reqDate, err := http.ParseTime(dateHeader)
+	if err != nil {
+		return errInvalidCredentials("malformed Date header")
+	}
+	skew := c.clockSkew
+	if skew == 0 {
+		skew = defaultClockSkew
+	}
+	if d := time.Since(reqDate); d > skew || d < -skew {
+		return errInvalidCredentials("request date outside allowed clock skew")
+	}
+	return nil
+}
+
+// findSigningKey resolves a signature's keyId, which may be either a bare
+// key fingerprint or a full "/:account/keys/:name" path, to a registered
+// key.
+func (c *CloudAPI) findSigningKey(keyID string) (*cloudapi.Key, error) {
+	name := keyID
+	if idx := strings.LastIndex(keyID, "/keys/"); idx != -1 {
+		name = keyID[idx+len("/keys/"):]
+	}
+	if key, err := c.GetKey(name); err == nil && key != nil {
+		return key, nil
+	}
+
+	keys, err := c.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Fingerprint == name || keys[i].Name == name {
+			return &keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no key matching %q", keyID)
+}
+
+// parseAuthorizationHeader parses the keyId, algorithm, headers and
+// signature parameters out of a `Signature ...` Authorization header.
+func parseAuthorizationHeader(header string) (*httpSignature, error) {
+	const scheme = "Signature "
+	if !strings.HasPrefix(header, scheme) {
+		return nil, fmt.Errorf("unsupported authorization scheme")
+	}
+
+	params := map[string]string{}
+	for _, part := range splitSignatureParams(strings.TrimPrefix(header, scheme)) {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[kv[0]] = strings.Trim(kv[1], `"`)
+	}
+
+	if params["keyId"] == "" || params["signature"] == "" {
+		return nil, fmt.Errorf("missing keyId or signature parameter")
+	}
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature parameter: %s", err)
+	}
+
+	headers := strings.Fields(params["headers"])
+	if len(headers) == 0 {
+		headers = []string{"date"}
+	}
+	algorithm := params["algorithm"]
+	if algorithm == "" {
+		algorithm = "rsa-sha256"
+	}
+
+	return &httpSignature{
+		keyID:     params["keyId"],
+		algorithm: algorithm,
+		headers:   headers,
+		signature: signature,
+	}, nil
+}
+
+// splitSignatureParams splits a comma separated list of key="value" pairs,
+// ignoring commas that appear inside quoted values (e.g. the space
+// separated "headers" list).
+func splitSignatureParams(s string) []string {
+	var parts []string
+	var cur strings.Builder
+	inQuotes := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			cur.WriteRune(r)
+		case r == ',' && !inQuotes:
+			parts = append(parts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, strings.TrimSpace(cur.String()))
+	}
+	return parts
+}
+
+// buildSigningString reconstructs the canonical string the client signed,
+// per draft-cavage-http-signatures: the named headers in order, each
+// rendered as "name: value", with the special "(request-target)" header
+// rendered as "method path".
+func buildSigningString(r *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, h := range headers {
+		h = strings.ToLower(h)
+		if h == "(request-target)" {
+			lines = append(lines, fmt.Sprintf("(request-target): %s %s", strings.ToLower(r.Method), r.URL.RequestURI()))
+			continue
+		}
+		v := r.Header.Get(h)
+		if v == "" {
+			return "", fmt.Errorf("missing header %q required by signature", h)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", h, v))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// verifySignatureBytes checks signature against signingString using pub,
+// supporting the RSA and ECDSA algorithms gosdc's signers use.
+func verifySignatureBytes(pub crypto.PublicKey, algorithm, signingString string, signature []byte) error {
+	var hash crypto.Hash
+	switch {
+	case strings.HasSuffix(algorithm, "sha256"):
+		hash = crypto.SHA256
+	case strings.HasSuffix(algorithm, "sha1"):
+		hash = crypto.SHA1
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", algorithm)
+	}
+	h := hash.New()
+	h.Write([]byte(signingString))
+	digest := h.Sum(nil)
+
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			return fmt.Errorf("ecdsa signature did not verify")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// errInvalidCredentials builds the 401 response CloudAPI returns for
+// authentication failures.
+func errInvalidCredentials(message string) *ErrorResponse {
+	return &ErrorResponse{http.StatusUnauthorized, "InvalidCredentialsError", message, nil}
+}
@@ -0,0 +1,189 @@
+//
+// gosdc - Go library to interact with the Joyent CloudAPI
+//
+// CloudAPI double testing service - HTTP Signature auth tests
+//
+// Copyright (c) Joyent Inc.
+//
+
+package cloudapi
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// signingFixture is an RSA keypair and its OpenSSH authorized_keys-format
+// public key, used to build a signed request the same way a real gosdc
+// client would.
+type signingFixture struct {
+	private   *rsa.PrivateKey
+	publicKey string
+}
+
+func newSigningFixture(t *testing.T) *signingFixture {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("NewPublicKey: %v", err)
+	}
+	return &signingFixture{
+		private:   priv,
+		publicKey: string(ssh.MarshalAuthorizedKey(sshPub)),
+	}
+}
+
+// sign adds a Date header and a draft-cavage-http-signatures Authorization
+// header covering "date" and "(request-target)" to req, using keyID as the
+// signature's keyId parameter.
+func (f *signingFixture) sign(t *testing.T, req *http.Request, keyID string) {
+	t.Helper()
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	signingString := fmt.Sprintf("(request-target): %s %s\ndate: %s",
+		strings.ToLower(req.Method), req.URL.RequestURI(), req.Header.Get("Date"))
+
+	h := sha256.Sum256([]byte(signingString))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, f.private, crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15: %v", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		`Signature keyId="%s",algorithm="rsa-sha256",headers="(request-target) date",signature="%s"`,
+		keyID, base64.StdEncoding.EncodeToString(sig)))
+}
+
+func TestVerifyRequestSignatureByFingerprint(t *testing.T) {
+	fixture := newSigningFixture(t)
+	c, srv := newTestServer(t, RequireHTTPSignatures(0))
+	key, err := c.CreateKey("mykey", fixture.publicKey)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", srv.URL+"/"+testUserAccount+"/machines", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	fixture.sign(t, req, key.Fingerprint)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestVerifyRequestSignatureByAccountKeyPath(t *testing.T) {
+	fixture := newSigningFixture(t)
+	c, srv := newTestServer(t, RequireHTTPSignatures(0))
+	if _, err := c.CreateKey("mykey", fixture.publicKey); err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", srv.URL+"/"+testUserAccount+"/machines", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	fixture.sign(t, req, "/"+testUserAccount+"/keys/mykey")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestVerifyRequestSignatureMissingAuthorizationRejected(t *testing.T) {
+	_, srv := newTestServer(t, RequireHTTPSignatures(0))
+
+	req, err := http.NewRequest("GET", srv.URL+"/"+testUserAccount+"/machines", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestVerifyRequestSignatureUnknownKeyRejected(t *testing.T) {
+	fixture := newSigningFixture(t)
+	_, srv := newTestServer(t, RequireHTTPSignatures(0))
+
+	req, err := http.NewRequest("GET", srv.URL+"/"+testUserAccount+"/machines", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	fixture.sign(t, req, "never-registered")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestVerifyRequestSignatureStaleDateRejected(t *testing.T) {
+	fixture := newSigningFixture(t)
+	c, srv := newTestServer(t, RequireHTTPSignatures(time.Minute))
+	key, err := c.CreateKey("mykey", fixture.publicKey)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", srv.URL+"/"+testUserAccount+"/machines", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	signingString := fmt.Sprintf("(request-target): %s %s\ndate: %s",
+		strings.ToLower(req.Method), req.URL.RequestURI(), req.Header.Get("Date"))
+	h := sha256.Sum256([]byte(signingString))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, fixture.private, crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15: %v", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(
+		`Signature keyId="%s",algorithm="rsa-sha256",headers="(request-target) date",signature="%s"`,
+		key.Fingerprint, base64.StdEncoding.EncodeToString(sig)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
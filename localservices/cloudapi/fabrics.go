@@ -0,0 +1,114 @@
+package cloudapi
+
+import (
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// fabricStore holds the in-memory fabrics/VLANs/networks the double serves.
+// CloudAPI only models the "default" fabric, matching every other store on
+// this double, which only ever serves a single account.
+type fabricStore struct {
+	mu       sync.Mutex
+	vlans    map[int16]*cloudapi.FabricVLAN
+	networks map[int16][]*cloudapi.FabricNetwork
+}
+
+// AddFabricVLAN registers a new VLAN on the default fabric.
+func (c *CloudAPI) AddFabricVLAN(vlan *cloudapi.FabricVLAN) (*cloudapi.FabricVLAN, error) {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	if c.fabrics.vlans == nil {
+		c.fabrics.vlans = map[int16]*cloudapi.FabricVLAN{}
+	}
+	if _, ok := c.fabrics.vlans[vlan.Id]; ok {
+		return nil, ErrInvalidArgument
+	}
+	c.fabrics.vlans[vlan.Id] = vlan
+	return vlan, nil
+}
+
+// ListFabricVLANs returns every VLAN registered on the default fabric.
+func (c *CloudAPI) ListFabricVLANs() ([]*cloudapi.FabricVLAN, error) {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	vlans := make([]*cloudapi.FabricVLAN, 0, len(c.fabrics.vlans))
+	for _, vlan := range c.fabrics.vlans {
+		vlans = append(vlans, vlan)
+	}
+	return vlans, nil
+}
+
+// GetFabricVLAN looks up a single VLAN by id.
+func (c *CloudAPI) GetFabricVLAN(vlanID int16) (*cloudapi.FabricVLAN, error) {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	vlan, ok := c.fabrics.vlans[vlanID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return vlan, nil
+}
+
+// DeleteFabricVLAN removes a VLAN and the fabric networks on it.
+func (c *CloudAPI) DeleteFabricVLAN(vlanID int16) error {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	if _, ok := c.fabrics.vlans[vlanID]; !ok {
+		return ErrNotFound
+	}
+	delete(c.fabrics.vlans, vlanID)
+	delete(c.fabrics.networks, vlanID)
+	return nil
+}
+
+// CreateFabricNetwork adds a network to the given VLAN.
+func (c *CloudAPI) CreateFabricNetwork(vlanID int16, network *cloudapi.FabricNetwork) (*cloudapi.FabricNetwork, error) {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	if _, ok := c.fabrics.vlans[vlanID]; !ok {
+		return nil, ErrNotFound
+	}
+	if c.fabrics.networks == nil {
+		c.fabrics.networks = map[int16][]*cloudapi.FabricNetwork{}
+	}
+	c.fabrics.networks[vlanID] = append(c.fabrics.networks[vlanID], network)
+	return network, nil
+}
+
+// ListFabricNetworks returns every network on the given VLAN.
+func (c *CloudAPI) ListFabricNetworks(vlanID int16) ([]*cloudapi.FabricNetwork, error) {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	if _, ok := c.fabrics.vlans[vlanID]; !ok {
+		return nil, ErrNotFound
+	}
+	return c.fabrics.networks[vlanID], nil
+}
+
+// GetFabricNetwork looks up a single fabric network by id.
+func (c *CloudAPI) GetFabricNetwork(vlanID int16, networkID string) (*cloudapi.FabricNetwork, error) {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	for _, network := range c.fabrics.networks[vlanID] {
+		if network.Id == networkID {
+			return network, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// DeleteFabricNetwork removes a single fabric network by id.
+func (c *CloudAPI) DeleteFabricNetwork(vlanID int16, networkID string) error {
+	c.fabrics.mu.Lock()
+	defer c.fabrics.mu.Unlock()
+	networks := c.fabrics.networks[vlanID]
+	for i, network := range networks {
+		if network.Id == networkID {
+			c.fabrics.networks[vlanID] = append(networks[:i], networks[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,78 @@
+//
+// gosdc - Go library to interact with the Joyent CloudAPI
+//
+// CloudAPI double testing service - fabrics/VLANs/NICs tests
+//
+// Copyright (c) Joyent Inc.
+//
+
+package cloudapi
+
+import (
+	"testing"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+const (
+	testMachineName = "test-machine"
+	testImage       = "11223344-0a0a-ff99-11bb-0a1b2c3d4e5f"
+)
+
+func TestAddFabricVLANDuplicateRejected(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	vlan := &cloudapi.FabricVLAN{Id: 2, Name: "test-vlan"}
+	if _, err := c.AddFabricVLAN(vlan); err != nil {
+		t.Fatalf("AddFabricVLAN: %v", err)
+	}
+	if _, err := c.AddFabricVLAN(&cloudapi.FabricVLAN{Id: 2, Name: "other"}); err != ErrInvalidArgument {
+		t.Fatalf("got err %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestFabricNetworkRequiresKnownVLAN(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	_, err := c.CreateFabricNetwork(99, &cloudapi.FabricNetwork{Id: "net1"})
+	if err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeleteFabricVLANRemovesItsNetworks(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	if _, err := c.AddFabricVLAN(&cloudapi.FabricVLAN{Id: 3, Name: "test-vlan"}); err != nil {
+		t.Fatalf("AddFabricVLAN: %v", err)
+	}
+	if _, err := c.CreateFabricNetwork(3, &cloudapi.FabricNetwork{Id: "net1"}); err != nil {
+		t.Fatalf("CreateFabricNetwork: %v", err)
+	}
+	if err := c.DeleteFabricVLAN(3); err != nil {
+		t.Fatalf("DeleteFabricVLAN: %v", err)
+	}
+	if _, err := c.ListFabricNetworks(3); err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound after VLAN deletion", err)
+	}
+}
+
+func TestAddNICRequiresExistingMachine(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	_, err := c.AddNIC("no-such-machine", &cloudapi.NIC{MAC: "00:11:22:33:44:55"})
+	if err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound", err)
+	}
+}
+
+func TestAddNICOnExistingMachine(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+	nic, err := c.AddNIC(machine.Id, &cloudapi.NIC{MAC: "00:11:22:33:44:55"})
+	if err != nil {
+		t.Fatalf("AddNIC: %v", err)
+	}
+	if nic.MAC != "00:11:22:33:44:55" {
+		t.Fatalf("got nic %+v", nic)
+	}
+}
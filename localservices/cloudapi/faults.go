@@ -0,0 +1,89 @@
+package cloudapi
+
+import (
+	"sync"
+	"time"
+)
+
+// injectedError is a single fault queued for a route: resp is served times
+// times (or forever, if times <= 0) before the real handler runs again.
+type injectedError struct {
+	resp  *ErrorResponse
+	times int
+}
+
+// faultStore holds every fault injected via InjectError/InjectLatency,
+// keyed by "METHOD route" for errors and by route alone for latency.
+type faultStore struct {
+	mu      sync.Mutex
+	errors  map[string]*injectedError
+	latency map[string]time.Duration
+}
+
+func faultKey(method, route string) string {
+	return method + " " + route
+}
+
+// InjectError makes every matching request to method+route fail with resp
+// instead of running its real handler. times bounds how many requests are
+// affected; times <= 0 injects the fault indefinitely, until cleared by
+// another call to InjectError with times == 0 and a nil resp, or until the
+// count set by a positive times is exhausted.
+func (c *CloudAPI) InjectError(route, method string, resp *ErrorResponse, times int) {
+	c.faults.mu.Lock()
+	defer c.faults.mu.Unlock()
+	key := faultKey(method, route)
+	if resp == nil {
+		if c.faults.errors != nil {
+			delete(c.faults.errors, key)
+		}
+		return
+	}
+	if c.faults.errors == nil {
+		c.faults.errors = map[string]*injectedError{}
+	}
+	c.faults.errors[key] = &injectedError{resp: resp, times: times}
+}
+
+// InjectLatency makes every request to route sleep for d before its real
+// handler runs. A zero d clears any previously injected latency.
+func (c *CloudAPI) InjectLatency(route string, d time.Duration) {
+	c.faults.mu.Lock()
+	defer c.faults.mu.Unlock()
+	if d <= 0 {
+		if c.faults.latency != nil {
+			delete(c.faults.latency, route)
+		}
+		return
+	}
+	if c.faults.latency == nil {
+		c.faults.latency = map[string]time.Duration{}
+	}
+	c.faults.latency[route] = d
+}
+
+// injectedError returns the fault queued for method+route, if any,
+// decrementing and expiring it as configured.
+func (c *CloudAPI) injectedError(method, route string) *ErrorResponse {
+	c.faults.mu.Lock()
+	defer c.faults.mu.Unlock()
+	key := faultKey(method, route)
+	fault, ok := c.faults.errors[key]
+	if !ok {
+		return nil
+	}
+	if fault.times > 0 {
+		fault.times--
+		if fault.times == 0 {
+			delete(c.faults.errors, key)
+		}
+	}
+	return fault.resp
+}
+
+// injectedLatency returns the latency configured for route, if any.
+func (c *CloudAPI) injectedLatency(route string) time.Duration {
+	c.faults.mu.Lock()
+	defer c.faults.mu.Unlock()
+	return c.faults.latency[route]
+}
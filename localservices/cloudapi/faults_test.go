@@ -0,0 +1,74 @@
+//
+// gosdc - Go library to interact with the Joyent CloudAPI
+//
+// CloudAPI double testing service - fault injection tests
+//
+// Copyright (c) Joyent Inc.
+//
+
+package cloudapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInjectErrorCountLimited(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	c.InjectError("/:account/machines", "GET", ErrBadRequest, 2)
+
+	if resp := c.injectedError("GET", "/:account/machines"); resp != ErrBadRequest {
+		t.Fatalf("1st call: got %v, want ErrBadRequest", resp)
+	}
+	if resp := c.injectedError("GET", "/:account/machines"); resp != ErrBadRequest {
+		t.Fatalf("2nd call: got %v, want ErrBadRequest", resp)
+	}
+	if resp := c.injectedError("GET", "/:account/machines"); resp != nil {
+		t.Fatalf("3rd call: got %v, want fault to have expired", resp)
+	}
+}
+
+func TestInjectErrorIndefinite(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	c.InjectError("/:account/machines", "GET", ErrBadRequest, 0)
+
+	for i := 0; i < 5; i++ {
+		if resp := c.injectedError("GET", "/:account/machines"); resp != ErrBadRequest {
+			t.Fatalf("call %d: got %v, want ErrBadRequest to persist", i, resp)
+		}
+	}
+
+	c.InjectError("/:account/machines", "GET", nil, 0)
+	if resp := c.injectedError("GET", "/:account/machines"); resp != nil {
+		t.Fatalf("got %v after clearing, want nil", resp)
+	}
+}
+
+func TestInjectErrorOverHTTP(t *testing.T) {
+	c, srv := newTestServer(t)
+	c.InjectError("/:account/machines", "GET", ErrBadRequest, 1)
+
+	resp := doRequest(t, srv, "GET", "/"+testUserAccount+"/machines", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	resp = doRequest(t, srv, "GET", "/"+testUserAccount+"/machines", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("fault should have expired: got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestInjectLatency(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	c.InjectLatency("/:account/machines", 10*time.Millisecond)
+	if d := c.injectedLatency("/:account/machines"); d != 10*time.Millisecond {
+		t.Fatalf("got latency %v, want 10ms", d)
+	}
+
+	c.InjectLatency("/:account/machines", 0)
+	if d := c.injectedLatency("/:account/machines"); d != 0 {
+		t.Fatalf("got latency %v after clearing, want 0", d)
+	}
+}
@@ -0,0 +1,98 @@
+package cloudapi
+
+import (
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// fwRuleStore holds the firewall rules registered on the account, keyed by
+// id.
+type fwRuleStore struct {
+	mu    sync.Mutex
+	rules map[string]*cloudapi.FirewallRule
+}
+
+// ListFirewallRules returns every firewall rule on the account.
+func (c *CloudAPI) ListFirewallRules() ([]*cloudapi.FirewallRule, error) {
+	c.fwrules.mu.Lock()
+	defer c.fwrules.mu.Unlock()
+	rules := make([]*cloudapi.FirewallRule, 0, len(c.fwrules.rules))
+	for _, rule := range c.fwrules.rules {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// GetFirewallRule looks up a single firewall rule by id.
+func (c *CloudAPI) GetFirewallRule(id string) (*cloudapi.FirewallRule, error) {
+	c.fwrules.mu.Lock()
+	defer c.fwrules.mu.Unlock()
+	rule, ok := c.fwrules.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return rule, nil
+}
+
+// CreateFirewallRule registers a new firewall rule.
+func (c *CloudAPI) CreateFirewallRule(rule string, enabled bool) (*cloudapi.FirewallRule, error) {
+	id, err := newResourceID()
+	if err != nil {
+		return nil, err
+	}
+	fwRule := &cloudapi.FirewallRule{Id: id, Rule: rule, Enabled: enabled}
+
+	c.fwrules.mu.Lock()
+	defer c.fwrules.mu.Unlock()
+	if c.fwrules.rules == nil {
+		c.fwrules.rules = map[string]*cloudapi.FirewallRule{}
+	}
+	c.fwrules.rules[id] = fwRule
+	return fwRule, nil
+}
+
+// UpdateFirewallRule replaces a firewall rule's text and enabled state.
+func (c *CloudAPI) UpdateFirewallRule(id, rule string, enabled bool) (*cloudapi.FirewallRule, error) {
+	c.fwrules.mu.Lock()
+	defer c.fwrules.mu.Unlock()
+	fwRule, ok := c.fwrules.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	fwRule.Rule = rule
+	fwRule.Enabled = enabled
+	return fwRule, nil
+}
+
+// EnableFirewallRule turns on a firewall rule.
+func (c *CloudAPI) EnableFirewallRule(id string) (*cloudapi.FirewallRule, error) {
+	return c.setFirewallRuleEnabled(id, true)
+}
+
+// DisableFirewallRule turns off a firewall rule.
+func (c *CloudAPI) DisableFirewallRule(id string) (*cloudapi.FirewallRule, error) {
+	return c.setFirewallRuleEnabled(id, false)
+}
+
+func (c *CloudAPI) setFirewallRuleEnabled(id string, enabled bool) (*cloudapi.FirewallRule, error) {
+	c.fwrules.mu.Lock()
+	defer c.fwrules.mu.Unlock()
+	fwRule, ok := c.fwrules.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	fwRule.Enabled = enabled
+	return fwRule, nil
+}
+
+// DeleteFirewallRule removes a firewall rule by id.
+func (c *CloudAPI) DeleteFirewallRule(id string) error {
+	c.fwrules.mu.Lock()
+	defer c.fwrules.mu.Unlock()
+	if _, ok := c.fwrules.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(c.fwrules.rules, id)
+	return nil
+}
@@ -0,0 +1,23 @@
+package cloudapi
+
+import (
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleMachineAudit handles GET /:account/machines/:id/audit.
+func handleMachineAudit(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	history, err := c.MachineAudit(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if history == nil {
+		history = []*cloudapi.AuditAction{}
+	}
+	return sendJSON(http.StatusOK, history, w, r)
+}
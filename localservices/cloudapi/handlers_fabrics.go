@@ -0,0 +1,167 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+func parseVLANID(ps httprouter.Params) (int16, error) {
+	id, err := strconv.ParseInt(ps.ByName("vlan_id"), 10, 16)
+	if err != nil {
+		return 0, ErrNotFound
+	}
+	return int16(id), nil
+}
+
+// handleListFabricVLANs handles GET /:account/fabrics/default/vlans.
+func handleListFabricVLANs(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlans, err := c.ListFabricVLANs()
+	if err != nil {
+		return err
+	}
+	if vlans == nil {
+		vlans = []*cloudapi.FabricVLAN{}
+	}
+	return sendJSON(http.StatusOK, vlans, w, r)
+}
+
+// handleCreateFabricVLAN handles POST /:account/fabrics/default/vlans.
+func handleCreateFabricVLAN(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlan := &cloudapi.FabricVLAN{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, vlan); err != nil {
+			return err
+		}
+	}
+	vlan, err = c.AddFabricVLAN(vlan)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusCreated, vlan, w, r)
+}
+
+// handleGetFabricVLAN handles GET /:account/fabrics/default/vlans/:vlan_id.
+func handleGetFabricVLAN(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlanID, err := parseVLANID(ps)
+	if err != nil {
+		return err
+	}
+	vlan, err := c.GetFabricVLAN(vlanID)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, vlan, w, r)
+}
+
+// handleDeleteFabricVLAN handles DELETE /:account/fabrics/default/vlans/:vlan_id.
+func handleDeleteFabricVLAN(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlanID, err := parseVLANID(ps)
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteFabricVLAN(vlanID); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
+
+// handleListFabricNetworks handles
+// GET /:account/fabrics/default/vlans/:vlan_id/networks.
+func handleListFabricNetworks(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlanID, err := parseVLANID(ps)
+	if err != nil {
+		return err
+	}
+	networks, err := c.ListFabricNetworks(vlanID)
+	if err != nil {
+		return err
+	}
+	if networks == nil {
+		networks = []*cloudapi.FabricNetwork{}
+	}
+	return sendJSON(http.StatusOK, networks, w, r)
+}
+
+// handleCreateFabricNetwork handles
+// POST /:account/fabrics/default/vlans/:vlan_id/networks.
+func handleCreateFabricNetwork(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlanID, err := parseVLANID(ps)
+	if err != nil {
+		return err
+	}
+	network := &cloudapi.FabricNetwork{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, network); err != nil {
+			return err
+		}
+	}
+	network, err = c.CreateFabricNetwork(vlanID, network)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusCreated, network, w, r)
+}
+
+// handleGetFabricNetwork handles
+// GET /:account/fabrics/default/vlans/:vlan_id/networks/:id.
+func handleGetFabricNetwork(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlanID, err := parseVLANID(ps)
+	if err != nil {
+		return err
+	}
+	network, err := c.GetFabricNetwork(vlanID, ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, network, w, r)
+}
+
+// handleDeleteFabricNetwork handles
+// DELETE /:account/fabrics/default/vlans/:vlan_id/networks/:id.
+func handleDeleteFabricNetwork(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	vlanID, err := parseVLANID(ps)
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteFabricNetwork(vlanID, ps.ByName("id")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
@@ -0,0 +1,133 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListFirewallRules handles GET /:account/fwrules.
+func handleListFirewallRules(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	fwRules, err := c.ListFirewallRules()
+	if err != nil {
+		return err
+	}
+	if fwRules == nil {
+		fwRules = []*cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusOK, fwRules, w, r)
+}
+
+// handleGetFirewallRule handles GET /:account/fwrules/:id.
+func handleGetFirewallRule(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	fwRule, err := c.GetFirewallRule(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if fwRule == nil {
+		fwRule = &cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusOK, fwRule, w, r)
+}
+
+func readFwRuleOpts(r *http.Request) (string, bool, error) {
+	opts := &cloudapi.CreateFwRuleOpts{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", false, err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, opts); err != nil {
+			return "", false, err
+		}
+	}
+	return opts.Rule, opts.Enabled, nil
+}
+
+// handleCreateFirewallRule handles POST /:account/fwrules.
+func handleCreateFirewallRule(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	rule, enabled, err := readFwRuleOpts(r)
+	if err != nil {
+		return err
+	}
+	fwRule, err := c.CreateFirewallRule(rule, enabled)
+	if err != nil {
+		return err
+	}
+	if fwRule == nil {
+		fwRule = &cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusCreated, fwRule, w, r)
+}
+
+// handleUpdateFirewallRule handles POST /:account/fwrules/:id.
+func handleUpdateFirewallRule(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	rule, enabled, err := readFwRuleOpts(r)
+	if err != nil {
+		return err
+	}
+	fwRule, err := c.UpdateFirewallRule(ps.ByName("id"), rule, enabled)
+	if err != nil {
+		return err
+	}
+	if fwRule == nil {
+		fwRule = &cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusOK, fwRule, w, r)
+}
+
+// handleEnableFirewallRule handles POST /:account/fwrules/:id/enable.
+func handleEnableFirewallRule(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	fwRule, err := c.EnableFirewallRule(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if fwRule == nil {
+		fwRule = &cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusOK, fwRule, w, r)
+}
+
+// handleDisableFirewallRule handles POST /:account/fwrules/:id/disable.
+func handleDisableFirewallRule(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	fwRule, err := c.DisableFirewallRule(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if fwRule == nil {
+		fwRule = &cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusOK, fwRule, w, r)
+}
+
+// handleDeleteFirewallRule handles DELETE /:account/fwrules/:id.
+func handleDeleteFirewallRule(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteFirewallRule(ps.ByName("id")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
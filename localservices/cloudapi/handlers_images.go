@@ -0,0 +1,89 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListImages handles GET /:account/images.
+func handleListImages(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	images, err := c.ListImages(processFilter(r.URL.RawQuery))
+	if err != nil {
+		return err
+	}
+	if images == nil {
+		images = []cloudapi.Image{}
+	}
+	return sendJSON(http.StatusOK, images, w, r)
+}
+
+// handleGetImage handles GET /:account/images/:id.
+func handleGetImage(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	image, err := c.GetImage(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		image = &cloudapi.Image{}
+	}
+	return sendJSON(http.StatusOK, image, w, r)
+}
+
+// handleCreateImageFromMachine handles POST /:account/images.
+func handleCreateImageFromMachine(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	opts := cloudapi.CreateImageFromMachineOpts{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &opts); err != nil {
+			return err
+		}
+	}
+	image, err := c.CreateImageFromMachine(opts)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusCreated, image, w, r)
+}
+
+// handleImageAction handles POST /:account/images/:id, currently only the
+// ?action=export variant.
+func handleImageAction(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if r.URL.Query().Get("action") != "export" {
+		return ErrNotAllowed
+	}
+	manifest, err := c.ExportImage(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, manifest, w, r)
+}
+
+// handleDeleteImage handles DELETE /:account/images/:id.
+func handleDeleteImage(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteImage(ps.ByName("id")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
@@ -0,0 +1,82 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListKeys handles GET /:account/keys.
+func handleListKeys(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	keys, err := c.ListKeys()
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		keys = []cloudapi.Key{}
+	}
+	return sendJSON(http.StatusOK, keys, w, r)
+}
+
+// handleGetKey handles GET /:account/keys/:name.
+func handleGetKey(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	key, err := c.GetKey(ps.ByName("name"))
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		key = &cloudapi.Key{}
+	}
+	return sendJSON(http.StatusOK, key, w, r)
+}
+
+// handleCreateKey handles POST /:account/keys.
+func handleCreateKey(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	var (
+		name string
+		key  string
+	)
+	opts := &cloudapi.CreateKeyOpts{}
+	body, errB := ioutil.ReadAll(r.Body)
+	if errB != nil {
+		return errB
+	}
+	if len(body) > 0 {
+		if errJ := json.Unmarshal(body, opts); errJ != nil {
+			return errJ
+		}
+		name = opts.Name
+		key = opts.Key
+	}
+	k, err := c.CreateKey(name, key)
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		k = &cloudapi.Key{}
+	}
+	return sendJSON(http.StatusCreated, k, w, r)
+}
+
+// handleDeleteKey handles DELETE /:account/keys/:name.
+func handleDeleteKey(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteKey(ps.ByName("name")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
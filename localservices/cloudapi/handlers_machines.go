@@ -0,0 +1,203 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListMachines handles GET /:account/machines.
+func handleListMachines(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	machines, err := c.ListMachines(processFilter(r.URL.RawQuery))
+	if err != nil {
+		return err
+	}
+	if machines == nil {
+		machines = []*cloudapi.Machine{}
+	}
+	return sendJSON(http.StatusOK, machines, w, r)
+}
+
+// handleCountMachines handles HEAD /:account/machines.
+func handleCountMachines(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	count, err := c.CountMachines()
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, count, w, r)
+}
+
+// handleGetMachine handles GET /:account/machines/:id.
+func handleGetMachine(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	machine, err := c.GetMachine(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if machine == nil {
+		machine = &cloudapi.Machine{}
+	}
+	return sendJSON(http.StatusOK, machine, w, r)
+}
+
+// handleDeleteMachine handles DELETE /:account/machines/:id.
+func handleDeleteMachine(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteMachine(ps.ByName("id")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
+
+// handleCreateMachine handles POST /:account/machines.
+func handleCreateMachine(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	var (
+		name     string
+		pkg      string
+		image    string
+		networks []string
+		metadata = map[string]string{}
+		tags     = map[string]string{}
+	)
+	opts := map[string]interface{}{}
+	body, errB := ioutil.ReadAll(r.Body)
+	if errB != nil {
+		return errB
+	}
+	if len(body) > 0 {
+		if errJ := json.Unmarshal(body, &opts); errJ != nil {
+			return errJ
+		}
+		for k, v := range opts {
+			if v == nil {
+				continue
+			}
+
+			switch k {
+			case "name":
+				name = v.(string)
+			case "package":
+				pkg = v.(string)
+			case "image":
+				image = v.(string)
+			case "networks":
+				networks = []string{}
+				for _, n := range v.([]interface{}) {
+					networks = append(networks, n.(string))
+				}
+			default:
+				if strings.HasPrefix(k, "tag.") {
+					tags[k[4:]] = v.(string)
+					continue
+				}
+				if strings.HasPrefix(k, "metadata.") {
+					metadata[k[9:]] = v.(string)
+					continue
+				}
+			}
+		}
+	}
+	machine, err := c.CreateMachine(name, pkg, image, networks, metadata, tags)
+	if err != nil {
+		return err
+	}
+	if machine == nil {
+		machine = &cloudapi.Machine{}
+	}
+	return sendJSON(http.StatusCreated, machine, w, r)
+}
+
+// handleListMachineFirewallRules handles GET /:account/machines/:id/fwrules.
+func handleListMachineFirewallRules(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	fwRules, err := c.ListMachineFirewallRules(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if fwRules == nil {
+		fwRules = []*cloudapi.FirewallRule{}
+	}
+	return sendJSON(http.StatusOK, fwRules, w, r)
+}
+
+// machineAction is a single machine action dispatched via the ?action= query
+// parameter of POST /:account/machines/:id.
+type machineAction func(c *CloudAPI, machineID string, r *http.Request) error
+
+// machineActions maps the ?action= query value to its handler, so that
+// handleMachineAction can dispatch without a growing if/else chain.
+var machineActions = map[string]machineAction{
+	"stop":             stopMachineAction,
+	"start":            startMachineAction,
+	"reboot":           rebootMachineAction,
+	"resize":           resizeMachineAction,
+	"rename":           renameMachineAction,
+	"enable_firewall":  enableFirewallMachineAction,
+	"disable_firewall": disableFirewallMachineAction,
+}
+
+func stopMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.StopMachine(machineID)
+}
+
+func startMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.StartMachine(machineID)
+}
+
+func rebootMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.RebootMachine(machineID)
+}
+
+func resizeMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.ResizeMachine(machineID, r.URL.Query().Get("package"))
+}
+
+func renameMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.RenameMachine(machineID, r.URL.Query().Get("name"))
+}
+
+func enableFirewallMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.EnableFirewallMachine(machineID)
+}
+
+func disableFirewallMachineAction(c *CloudAPI, machineID string, r *http.Request) error {
+	return c.DisableFirewallMachine(machineID)
+}
+
+// handleMachineAction handles POST /:account/machines/:id, dispatching on
+// the ?action= query parameter.
+func handleMachineAction(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	actionName := r.URL.Query().Get("action")
+	action, ok := machineActions[actionName]
+	if !ok {
+		return ErrNotAllowed
+	}
+	err := action(c, ps.ByName("id"), r)
+	c.recordMachineAudit(ps.ByName("id"), actionName, err == nil)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusAccepted, nil, w, r)
+}
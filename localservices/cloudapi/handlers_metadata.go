@@ -0,0 +1,83 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleGetMachineMetadata handles GET /:account/machines/:id/metadata.
+func handleGetMachineMetadata(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	md, err := c.GetMachineMetadata(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, md, w, r)
+}
+
+// handleGetMachineMetadataKey handles
+// GET /:account/machines/:id/metadata/:key.
+func handleGetMachineMetadataKey(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	md, err := c.GetMachineMetadata(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	value, ok := md[ps.ByName("key")]
+	if !ok {
+		return ErrNotFound
+	}
+	return sendJSON(http.StatusOK, value, w, r)
+}
+
+// handleUpdateMachineMetadata handles POST /:account/machines/:id/metadata.
+func handleUpdateMachineMetadata(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	updates := map[string]string{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &updates); err != nil {
+			return err
+		}
+	}
+	md, err := c.UpdateMachineMetadata(ps.ByName("id"), updates)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, md, w, r)
+}
+
+// handleDeleteMachineMetadataKey handles
+// DELETE /:account/machines/:id/metadata/:key.
+func handleDeleteMachineMetadataKey(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteMachineMetadata(ps.ByName("id"), ps.ByName("key")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
+
+// handleDeleteMachineMetadata handles DELETE /:account/machines/:id/metadata.
+func handleDeleteMachineMetadata(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteMachineMetadata(ps.ByName("id"), ""); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
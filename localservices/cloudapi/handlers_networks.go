@@ -0,0 +1,38 @@
+package cloudapi
+
+import (
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListNetworks handles GET /:account/networks.
+func handleListNetworks(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	networks, err := c.ListNetworks()
+	if err != nil {
+		return err
+	}
+	if networks == nil {
+		networks = []cloudapi.Network{}
+	}
+	return sendJSON(http.StatusOK, networks, w, r)
+}
+
+// handleGetNetwork handles GET /:account/networks/:id.
+func handleGetNetwork(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	network, err := c.GetNetwork(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if network == nil {
+		network = &cloudapi.Network{}
+	}
+	return sendJSON(http.StatusOK, network, w, r)
+}
@@ -0,0 +1,70 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListMachineNICs handles GET /:account/machines/:id/nics.
+func handleListMachineNICs(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	nics, err := c.ListNICs(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if nics == nil {
+		nics = []*cloudapi.NIC{}
+	}
+	return sendJSON(http.StatusOK, nics, w, r)
+}
+
+// handleCreateMachineNIC handles POST /:account/machines/:id/nics.
+func handleCreateMachineNIC(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	nic := &cloudapi.NIC{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, nic); err != nil {
+			return err
+		}
+	}
+	nic, err = c.AddNIC(ps.ByName("id"), nic)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusCreated, nic, w, r)
+}
+
+// handleGetMachineNIC handles GET /:account/machines/:id/nics/:mac.
+func handleGetMachineNIC(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	nic, err := c.GetNIC(ps.ByName("id"), ps.ByName("mac"))
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, nic, w, r)
+}
+
+// handleDeleteMachineNIC handles DELETE /:account/machines/:id/nics/:mac.
+func handleDeleteMachineNIC(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.RemoveNIC(ps.ByName("id"), ps.ByName("mac")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
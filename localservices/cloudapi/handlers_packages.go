@@ -0,0 +1,38 @@
+package cloudapi
+
+import (
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListPackages handles GET /:account/packages.
+func handleListPackages(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	pkgs, err := c.ListPackages(processFilter(r.URL.RawQuery))
+	if err != nil {
+		return err
+	}
+	if pkgs == nil {
+		pkgs = []cloudapi.Package{}
+	}
+	return sendJSON(http.StatusOK, pkgs, w, r)
+}
+
+// handleGetPackage handles GET /:account/packages/:name.
+func handleGetPackage(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	pkg, err := c.GetPackage(ps.ByName("name"))
+	if err != nil {
+		return err
+	}
+	if pkg == nil {
+		pkg = &cloudapi.Package{}
+	}
+	return sendJSON(http.StatusOK, pkg, w, r)
+}
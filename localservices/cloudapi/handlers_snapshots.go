@@ -0,0 +1,84 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListSnapshots handles GET /:account/machines/:id/snapshots.
+func handleListSnapshots(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	snaps, err := c.ListSnapshots(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	if snaps == nil {
+		snaps = []*cloudapi.Snapshot{}
+	}
+	return sendJSON(http.StatusOK, snaps, w, r)
+}
+
+// handleCreateSnapshot handles POST /:account/machines/:id/snapshots.
+func handleCreateSnapshot(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	opts := struct {
+		Name string `json:"name"`
+	}{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &opts); err != nil {
+			return err
+		}
+	}
+	snap, err := c.CreateSnapshot(ps.ByName("id"), opts.Name)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusCreated, snap, w, r)
+}
+
+// handleGetSnapshot handles GET /:account/machines/:id/snapshots/:name.
+func handleGetSnapshot(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	snap, err := c.GetSnapshot(ps.ByName("id"), ps.ByName("name"))
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, snap, w, r)
+}
+
+// handleStartMachineFromSnapshot handles
+// POST /:account/machines/:id/snapshots/:name.
+func handleStartMachineFromSnapshot(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.StartFromSnapshot(ps.ByName("id"), ps.ByName("name")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusAccepted, nil, w, r)
+}
+
+// handleDeleteSnapshot handles DELETE /:account/machines/:id/snapshots/:name.
+func handleDeleteSnapshot(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteSnapshot(ps.ByName("id"), ps.ByName("name")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
@@ -0,0 +1,107 @@
+package cloudapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// handleListMachineTags handles GET /:account/machines/:id/tags.
+func handleListMachineTags(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	tags, err := c.GetMachineTags(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, tags, w, r)
+}
+
+// handleGetMachineTag handles GET /:account/machines/:id/tags/:key.
+func handleGetMachineTag(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	tags, err := c.GetMachineTags(ps.ByName("id"))
+	if err != nil {
+		return err
+	}
+	value, ok := tags[ps.ByName("key")]
+	if !ok {
+		return ErrNotFound
+	}
+	return sendJSON(http.StatusOK, value, w, r)
+}
+
+func readTagsBody(r *http.Request) (map[string]string, error) {
+	tags := map[string]string{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &tags); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// handleUpdateMachineTags handles POST /:account/machines/:id/tags, merging
+// the given tags into the machine's existing set.
+func handleUpdateMachineTags(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	updates, err := readTagsBody(r)
+	if err != nil {
+		return err
+	}
+	tags, err := c.UpdateMachineTags(ps.ByName("id"), updates)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, tags, w, r)
+}
+
+// handleReplaceMachineTags handles PUT /:account/machines/:id/tags,
+// replacing the machine's entire tag set.
+func handleReplaceMachineTags(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	replacement, err := readTagsBody(r)
+	if err != nil {
+		return err
+	}
+	tags, err := c.ReplaceMachineTags(ps.ByName("id"), replacement)
+	if err != nil {
+		return err
+	}
+	return sendJSON(http.StatusOK, tags, w, r)
+}
+
+// handleDeleteMachineTag handles DELETE /:account/machines/:id/tags/:key.
+func handleDeleteMachineTag(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteMachineTags(ps.ByName("id"), ps.ByName("key")); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
+
+// handleDeleteMachineTags handles DELETE /:account/machines/:id/tags.
+func handleDeleteMachineTags(c *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error {
+	if err := c.checkAccount(ps); err != nil {
+		return err
+	}
+	if err := c.DeleteMachineTags(ps.ByName("id"), ""); err != nil {
+		return err
+	}
+	return sendJSON(http.StatusNoContent, nil, w, r)
+}
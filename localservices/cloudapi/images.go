@@ -0,0 +1,192 @@
+package cloudapi
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// defaultImageCreationPolls is how many GetImage/ListImages calls it takes a
+// freshly created image to flip from "creating" to "active" (it is returned
+// as "active" by the last of them), mirroring the async behaviour of the
+// real IMGAPI.
+const defaultImageCreationPolls = 1
+
+// imageTransitions tracks images that are still converging to their final
+// state, independent of whatever store backs ListImages/GetImage.
+type imageTransitions struct {
+	mu             sync.Mutex
+	remainingPolls map[string]int
+}
+
+// ErrImageInUse is returned when deleting an image still referenced by a
+// live machine.
+var ErrImageInUse = registerError(http.StatusConflict, "InUseError", "Image is in use by a machine")
+
+// ExportImageResult is the manifest location IMGAPI returns for
+// POST /:account/images/:id?action=export.
+type ExportImageResult struct {
+	ManifestPath string `json:"manifestPath"`
+	ImagePath    string `json:"imagePath"`
+}
+
+// WithImageCreationPolls overrides how many times a test must poll a
+// creating image before it becomes active, instead of the default of one.
+func WithImageCreationPolls(polls int) Option {
+	return func(c *CloudAPI) {
+		c.imageCreationPolls = polls
+	}
+}
+
+// ListImages returns every image matching filter. Only the "name" filter
+// CloudAPI commonly queries on is modeled; any other key is ignored.
+func (c *CloudAPI) ListImages(filter map[string]string) ([]cloudapi.Image, error) {
+	c.imagesMu.Lock()
+	defer c.imagesMu.Unlock()
+	images := make([]cloudapi.Image, 0, len(c.images))
+	for _, image := range c.images {
+		if name, ok := filter["name"]; ok && image.Name != name {
+			continue
+		}
+		c.advanceImageState(image)
+		images = append(images, *image)
+	}
+	return images, nil
+}
+
+// GetImage looks up a single image by id.
+func (c *CloudAPI) GetImage(id string) (*cloudapi.Image, error) {
+	c.imagesMu.Lock()
+	defer c.imagesMu.Unlock()
+	image, ok := c.images[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c.advanceImageState(image)
+	return image, nil
+}
+
+// CreateImageFromMachine snapshots opts.Machine into a new image in "creating"
+// state, as triggered by POST /:account/images.
+func (c *CloudAPI) CreateImageFromMachine(opts cloudapi.CreateImageFromMachineOpts) (*cloudapi.Image, error) {
+	machine, err := c.GetMachine(opts.Machine)
+	if err != nil {
+		return nil, err
+	}
+	if machine == nil || machine.Id == "" {
+		return nil, ErrNotFound
+	}
+
+	id, err := newImageID()
+	if err != nil {
+		return nil, err
+	}
+	img := &cloudapi.Image{
+		Id:          id,
+		Name:        opts.Name,
+		Version:     opts.Version,
+		Description: opts.Description,
+		Tags:        opts.Tags,
+		OS:          machine.Image,
+		State:       "creating",
+	}
+
+	c.imagesMu.Lock()
+	if c.images == nil {
+		c.images = map[string]*cloudapi.Image{}
+	}
+	c.images[img.Id] = img
+	c.imagesMu.Unlock()
+
+	polls := c.imageCreationPolls
+	if polls <= 0 {
+		polls = defaultImageCreationPolls
+	}
+	c.imageTransitions.mu.Lock()
+	if c.imageTransitions.remainingPolls == nil {
+		c.imageTransitions.remainingPolls = map[string]int{}
+	}
+	c.imageTransitions.remainingPolls[img.Id] = polls
+	c.imageTransitions.mu.Unlock()
+
+	return img, nil
+}
+
+// advanceImageState steps img's pending creating->active transition, if
+// any, mutating it in place so every holder of the pointer observes the
+// change.
+func (c *CloudAPI) advanceImageState(img *cloudapi.Image) {
+	if img == nil || img.State != "creating" {
+		return
+	}
+	c.imageTransitions.mu.Lock()
+	defer c.imageTransitions.mu.Unlock()
+	remaining, ok := c.imageTransitions.remainingPolls[img.Id]
+	if !ok {
+		return
+	}
+	remaining--
+	if remaining <= 0 {
+		img.State = "active"
+		delete(c.imageTransitions.remainingPolls, img.Id)
+		return
+	}
+	c.imageTransitions.remainingPolls[img.Id] = remaining
+}
+
+// ExportImage returns the manifest location for an already created image.
+func (c *CloudAPI) ExportImage(imageID string) (*ExportImageResult, error) {
+	c.imagesMu.Lock()
+	img, ok := c.images[imageID]
+	c.imagesMu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ExportImageResult{
+		ManifestPath: fmt.Sprintf("/%s/stor/images/%s/manifest.json", c.ServiceInstance.UserAccount, img.Id),
+		ImagePath:    fmt.Sprintf("/%s/stor/images/%s/file.zfs.gz", c.ServiceInstance.UserAccount, img.Id),
+	}, nil
+}
+
+// DeleteImage removes an image, refusing to do so while a machine still
+// references it.
+func (c *CloudAPI) DeleteImage(imageID string) error {
+	c.imagesMu.Lock()
+	_, ok := c.images[imageID]
+	c.imagesMu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+
+	machines, err := c.ListMachines(nil)
+	if err != nil {
+		return err
+	}
+	for _, machine := range machines {
+		if machine.Image == imageID && machine.State != "deleted" {
+			return ErrImageInUse
+		}
+	}
+
+	c.imagesMu.Lock()
+	delete(c.images, imageID)
+	c.imagesMu.Unlock()
+
+	c.imageTransitions.mu.Lock()
+	delete(c.imageTransitions.remainingPolls, imageID)
+	c.imageTransitions.mu.Unlock()
+	return nil
+}
+
+func newImageID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,112 @@
+//
+// gosdc - Go library to interact with the Joyent CloudAPI
+//
+// CloudAPI double testing service - image lifecycle tests
+//
+// Copyright (c) Joyent Inc.
+//
+
+package cloudapi
+
+import (
+	"testing"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+func TestCreateImageFromMachineBecomesActiveAfterPolls(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}, imageCreationPolls: 2}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	img, err := c.CreateImageFromMachine(cloudapi.CreateImageFromMachineOpts{Machine: machine.Id, Name: "my-image"})
+	if err != nil {
+		t.Fatalf("CreateImageFromMachine: %v", err)
+	}
+	if img.State != "creating" {
+		t.Fatalf("got state %q right after creation, want creating", img.State)
+	}
+
+	got, err := c.GetImage(img.Id)
+	if err != nil {
+		t.Fatalf("GetImage: %v", err)
+	}
+	if got.State != "creating" {
+		t.Fatalf("got state %q after first poll, want still creating", got.State)
+	}
+
+	got, err = c.GetImage(img.Id)
+	if err != nil {
+		t.Fatalf("GetImage: %v", err)
+	}
+	if got.State != "active" {
+		t.Fatalf("got state %q after second poll, want active", got.State)
+	}
+}
+
+func TestCreateImageFromMachineUnknownMachine(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	_, err := c.CreateImageFromMachine(cloudapi.CreateImageFromMachineOpts{Machine: "no-such-machine"})
+	if err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound", err)
+	}
+}
+
+func TestExportImage(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+	img, err := c.CreateImageFromMachine(cloudapi.CreateImageFromMachineOpts{Machine: machine.Id, Name: "my-image"})
+	if err != nil {
+		t.Fatalf("CreateImageFromMachine: %v", err)
+	}
+
+	result, err := c.ExportImage(img.Id)
+	if err != nil {
+		t.Fatalf("ExportImage: %v", err)
+	}
+	if result.ManifestPath == "" || result.ImagePath == "" {
+		t.Fatalf("got empty export paths: %+v", result)
+	}
+}
+
+func TestDeleteImageInUseRejected(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	source, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+	img, err := c.CreateImageFromMachine(cloudapi.CreateImageFromMachineOpts{Machine: source.Id, Name: "my-image"})
+	if err != nil {
+		t.Fatalf("CreateImageFromMachine: %v", err)
+	}
+	if _, err := c.CreateMachine("using-the-image", "Small", img.Id, nil, nil, nil); err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	if err := c.DeleteImage(img.Id); err != ErrImageInUse {
+		t.Fatalf("got err %v, want ErrImageInUse", err)
+	}
+}
+
+func TestDeleteImageNotInUse(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	source, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+	img, err := c.CreateImageFromMachine(cloudapi.CreateImageFromMachineOpts{Machine: source.Id, Name: "my-image"})
+	if err != nil {
+		t.Fatalf("CreateImageFromMachine: %v", err)
+	}
+	if err := c.DeleteImage(img.Id); err != nil {
+		t.Fatalf("DeleteImage: %v", err)
+	}
+	if _, err := c.GetImage(img.Id); err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound", err)
+	}
+}
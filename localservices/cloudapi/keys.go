@@ -0,0 +1,71 @@
+package cloudapi
+
+import (
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+	"golang.org/x/crypto/ssh"
+)
+
+// keyStore holds the SSH keys registered under this double's account, keyed
+// by name.
+type keyStore struct {
+	mu   sync.Mutex
+	keys map[string]*cloudapi.Key
+}
+
+// ListKeys returns every key registered on the account.
+func (c *CloudAPI) ListKeys() ([]cloudapi.Key, error) {
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	keys := make([]cloudapi.Key, 0, len(c.keys.keys))
+	for _, key := range c.keys.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+// GetKey looks up a single key by name.
+func (c *CloudAPI) GetKey(name string) (*cloudapi.Key, error) {
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	key, ok := c.keys.keys[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+// CreateKey registers a new SSH key under name, computing its fingerprint
+// from the given OpenSSH authorized_keys-format public key.
+func (c *CloudAPI) CreateKey(name, key string) (*cloudapi.Key, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return nil, ErrInvalidArgument
+	}
+
+	k := &cloudapi.Key{
+		Name:        name,
+		Fingerprint: ssh.FingerprintLegacyMD5(pub),
+		Key:         key,
+	}
+
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	if c.keys.keys == nil {
+		c.keys.keys = map[string]*cloudapi.Key{}
+	}
+	c.keys.keys[name] = k
+	return k, nil
+}
+
+// DeleteKey removes a single key by name.
+func (c *CloudAPI) DeleteKey(name string) error {
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	if _, ok := c.keys.keys[name]; !ok {
+		return ErrNotFound
+	}
+	delete(c.keys.keys, name)
+	return nil
+}
@@ -0,0 +1,194 @@
+package cloudapi
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// machineStore holds the machines provisioned under this double's account,
+// keyed by id.
+type machineStore struct {
+	mu       sync.Mutex
+	machines map[string]*cloudapi.Machine
+}
+
+// ListMachines returns every machine matching filter. Only the "name" and
+// "state" filters CloudAPI commonly queries on are modeled; any other key
+// is ignored.
+func (c *CloudAPI) ListMachines(filter map[string]string) ([]*cloudapi.Machine, error) {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	machines := make([]*cloudapi.Machine, 0, len(c.machines.machines))
+	for _, machine := range c.machines.machines {
+		if name, ok := filter["name"]; ok && machine.Name != name {
+			continue
+		}
+		if state, ok := filter["state"]; ok && machine.State != state {
+			continue
+		}
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+// CountMachines returns the number of machines on the account.
+func (c *CloudAPI) CountMachines() (int, error) {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	return len(c.machines.machines), nil
+}
+
+// GetMachine looks up a single machine by id.
+func (c *CloudAPI) GetMachine(id string) (*cloudapi.Machine, error) {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	machine, ok := c.machines.machines[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return machine, nil
+}
+
+// CreateMachine provisions a new machine in the "running" state.
+func (c *CloudAPI) CreateMachine(name, pkg, image string, networks []string, metadata, tags map[string]string) (*cloudapi.Machine, error) {
+	id, err := newResourceID()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = id
+	}
+	machine := &cloudapi.Machine{
+		Id:       id,
+		Name:     name,
+		Package:  pkg,
+		Image:    image,
+		Networks: networks,
+		Metadata: metadata,
+		Tags:     tags,
+		State:    "running",
+	}
+
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	if c.machines.machines == nil {
+		c.machines.machines = map[string]*cloudapi.Machine{}
+	}
+	c.machines.machines[id] = machine
+	return machine, nil
+}
+
+// DeleteMachine removes a machine by id.
+func (c *CloudAPI) DeleteMachine(id string) error {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	if _, ok := c.machines.machines[id]; !ok {
+		return ErrNotFound
+	}
+	delete(c.machines.machines, id)
+	return nil
+}
+
+// StopMachine transitions a machine to the "stopped" state.
+func (c *CloudAPI) StopMachine(id string) error {
+	return c.setMachineState(id, "stopped")
+}
+
+// StartMachine transitions a machine to the "running" state.
+func (c *CloudAPI) StartMachine(id string) error {
+	return c.setMachineState(id, "running")
+}
+
+// RebootMachine leaves a machine running after restarting it.
+func (c *CloudAPI) RebootMachine(id string) error {
+	return c.setMachineState(id, "running")
+}
+
+func (c *CloudAPI) setMachineState(id, state string) error {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	machine, ok := c.machines.machines[id]
+	if !ok {
+		return ErrNotFound
+	}
+	machine.State = state
+	return nil
+}
+
+// ResizeMachine changes a machine's package.
+func (c *CloudAPI) ResizeMachine(id, pkg string) error {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	machine, ok := c.machines.machines[id]
+	if !ok {
+		return ErrNotFound
+	}
+	machine.Package = pkg
+	return nil
+}
+
+// RenameMachine changes a machine's name.
+func (c *CloudAPI) RenameMachine(id, name string) error {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	machine, ok := c.machines.machines[id]
+	if !ok {
+		return ErrNotFound
+	}
+	machine.Name = name
+	return nil
+}
+
+// EnableFirewallMachine turns on a machine's firewall.
+func (c *CloudAPI) EnableFirewallMachine(id string) error {
+	return c.setMachineFirewall(id, true)
+}
+
+// DisableFirewallMachine turns off a machine's firewall.
+func (c *CloudAPI) DisableFirewallMachine(id string) error {
+	return c.setMachineFirewall(id, false)
+}
+
+func (c *CloudAPI) setMachineFirewall(id string, enabled bool) error {
+	c.machines.mu.Lock()
+	defer c.machines.mu.Unlock()
+	machine, ok := c.machines.machines[id]
+	if !ok {
+		return ErrNotFound
+	}
+	machine.FirewallEnabled = enabled
+	return nil
+}
+
+// ListMachineFirewallRules returns every firewall rule that targets
+// machineID, matched the same best-effort way real CloudAPI resolves a
+// rule's machine targets.
+func (c *CloudAPI) ListMachineFirewallRules(machineID string) ([]*cloudapi.FirewallRule, error) {
+	rules, err := c.ListFirewallRules()
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*cloudapi.FirewallRule, 0, len(rules))
+	for _, rule := range rules {
+		if strings.Contains(rule.Rule, machineID) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched, nil
+}
+
+// newResourceID generates a random UUID-v4-style identifier, used for
+// machines and firewall rules.
+func newResourceID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,61 @@
+package cloudapi
+
+import "sync"
+
+// metadataStore holds free-form metadata set on machines, keyed by machine
+// id and then metadata key.
+type metadataStore struct {
+	mu       sync.Mutex
+	metadata map[string]map[string]string
+}
+
+// GetMachineMetadata returns the full metadata map set on a machine through
+// UpdateMachineMetadata. Metadata passed to CreateMachine is kept on the
+// machine itself and is not included.
+func (c *CloudAPI) GetMachineMetadata(machineID string) (map[string]string, error) {
+	c.metadata.mu.Lock()
+	defer c.metadata.mu.Unlock()
+	md := map[string]string{}
+	for k, v := range c.metadata.metadata[machineID] {
+		md[k] = v
+	}
+	return md, nil
+}
+
+// UpdateMachineMetadata merges updates into a machine's metadata and
+// returns the resulting full map.
+func (c *CloudAPI) UpdateMachineMetadata(machineID string, updates map[string]string) (map[string]string, error) {
+	c.metadata.mu.Lock()
+	defer c.metadata.mu.Unlock()
+	if c.metadata.metadata == nil {
+		c.metadata.metadata = map[string]map[string]string{}
+	}
+	if c.metadata.metadata[machineID] == nil {
+		c.metadata.metadata[machineID] = map[string]string{}
+	}
+	for k, v := range updates {
+		c.metadata.metadata[machineID][k] = v
+	}
+
+	md := map[string]string{}
+	for k, v := range c.metadata.metadata[machineID] {
+		md[k] = v
+	}
+	return md, nil
+}
+
+// DeleteMachineMetadata deletes a single metadata key, or every key on the
+// machine when key is empty.
+func (c *CloudAPI) DeleteMachineMetadata(machineID, key string) error {
+	c.metadata.mu.Lock()
+	defer c.metadata.mu.Unlock()
+	if key == "" {
+		delete(c.metadata.metadata, machineID)
+		return nil
+	}
+	if _, ok := c.metadata.metadata[machineID][key]; !ok {
+		return ErrNotFound
+	}
+	delete(c.metadata.metadata[machineID], key)
+	return nil
+}
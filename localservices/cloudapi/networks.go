@@ -0,0 +1,47 @@
+package cloudapi
+
+import (
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// networkStore holds the fixed networks available on the account, keyed by
+// id. CloudAPI doesn't let clients create networks, so tests seed them with
+// AddNetwork.
+type networkStore struct {
+	mu       sync.Mutex
+	networks map[string]*cloudapi.Network
+}
+
+// AddNetwork registers a network fixture so it can be listed and fetched.
+func (c *CloudAPI) AddNetwork(network *cloudapi.Network) {
+	c.networks.mu.Lock()
+	defer c.networks.mu.Unlock()
+	if c.networks.networks == nil {
+		c.networks.networks = map[string]*cloudapi.Network{}
+	}
+	c.networks.networks[network.Id] = network
+}
+
+// ListNetworks returns every network registered on the account.
+func (c *CloudAPI) ListNetworks() ([]cloudapi.Network, error) {
+	c.networks.mu.Lock()
+	defer c.networks.mu.Unlock()
+	networks := make([]cloudapi.Network, 0, len(c.networks.networks))
+	for _, network := range c.networks.networks {
+		networks = append(networks, *network)
+	}
+	return networks, nil
+}
+
+// GetNetwork looks up a single network by id.
+func (c *CloudAPI) GetNetwork(id string) (*cloudapi.Network, error) {
+	c.networks.mu.Lock()
+	defer c.networks.mu.Unlock()
+	network, ok := c.networks.networks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return network, nil
+}
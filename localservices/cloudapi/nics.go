@@ -0,0 +1,69 @@
+package cloudapi
+
+import (
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// nicStore holds the NICs attached to machines, keyed by machine id and
+// then by MAC address.
+type nicStore struct {
+	mu   sync.Mutex
+	nics map[string]map[string]*cloudapi.NIC
+}
+
+// AddNIC attaches a NIC to a machine.
+func (c *CloudAPI) AddNIC(machineID string, nic *cloudapi.NIC) (*cloudapi.NIC, error) {
+	machine, err := c.GetMachine(machineID)
+	if err != nil {
+		return nil, err
+	}
+	if machine == nil || machine.Id == "" {
+		return nil, ErrNotFound
+	}
+
+	c.machineNICs.mu.Lock()
+	defer c.machineNICs.mu.Unlock()
+	if c.machineNICs.nics == nil {
+		c.machineNICs.nics = map[string]map[string]*cloudapi.NIC{}
+	}
+	if c.machineNICs.nics[machineID] == nil {
+		c.machineNICs.nics[machineID] = map[string]*cloudapi.NIC{}
+	}
+	c.machineNICs.nics[machineID][nic.MAC] = nic
+	return nic, nil
+}
+
+// ListNICs returns every NIC attached to a machine.
+func (c *CloudAPI) ListNICs(machineID string) ([]*cloudapi.NIC, error) {
+	c.machineNICs.mu.Lock()
+	defer c.machineNICs.mu.Unlock()
+	nics := make([]*cloudapi.NIC, 0, len(c.machineNICs.nics[machineID]))
+	for _, nic := range c.machineNICs.nics[machineID] {
+		nics = append(nics, nic)
+	}
+	return nics, nil
+}
+
+// GetNIC looks up a single NIC on a machine by MAC address.
+func (c *CloudAPI) GetNIC(machineID, mac string) (*cloudapi.NIC, error) {
+	c.machineNICs.mu.Lock()
+	defer c.machineNICs.mu.Unlock()
+	nic, ok := c.machineNICs.nics[machineID][mac]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return nic, nil
+}
+
+// RemoveNIC detaches a NIC from a machine.
+func (c *CloudAPI) RemoveNIC(machineID, mac string) error {
+	c.machineNICs.mu.Lock()
+	defer c.machineNICs.mu.Unlock()
+	if _, ok := c.machineNICs.nics[machineID][mac]; !ok {
+		return ErrNotFound
+	}
+	delete(c.machineNICs.nics[machineID], mac)
+	return nil
+}
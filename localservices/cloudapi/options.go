@@ -0,0 +1,18 @@
+package cloudapi
+
+import "time"
+
+// Option configures a *CloudAPI at SetupHTTP time.
+type Option func(c *CloudAPI)
+
+// RequireHTTPSignatures turns on verification of the Joyent HTTP Signature
+// scheme (draft-cavage-http-signatures) on every request, the same scheme
+// real CloudAPI enforces. clockSkew bounds how far a request's Date header
+// may drift from the server's clock before it is rejected as stale; a zero
+// value falls back to defaultClockSkew.
+func RequireHTTPSignatures(clockSkew time.Duration) Option {
+	return func(c *CloudAPI) {
+		c.authRequired = true
+		c.clockSkew = clockSkew
+	}
+}
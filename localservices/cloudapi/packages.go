@@ -0,0 +1,52 @@
+package cloudapi
+
+import (
+	"sync"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// packageStore holds the packages (instance sizes) available on the
+// account, keyed by name. CloudAPI doesn't let clients create packages, so
+// tests seed them with AddPackage.
+type packageStore struct {
+	mu       sync.Mutex
+	packages map[string]*cloudapi.Package
+}
+
+// AddPackage registers a package fixture so it can be listed and fetched.
+func (c *CloudAPI) AddPackage(pkg *cloudapi.Package) {
+	c.packages.mu.Lock()
+	defer c.packages.mu.Unlock()
+	if c.packages.packages == nil {
+		c.packages.packages = map[string]*cloudapi.Package{}
+	}
+	c.packages.packages[pkg.Name] = pkg
+}
+
+// ListPackages returns every package matching filter. Only the "name"
+// filter CloudAPI commonly queries on is modeled; any other key is
+// ignored.
+func (c *CloudAPI) ListPackages(filter map[string]string) ([]cloudapi.Package, error) {
+	c.packages.mu.Lock()
+	defer c.packages.mu.Unlock()
+	pkgs := make([]cloudapi.Package, 0, len(c.packages.packages))
+	for _, pkg := range c.packages.packages {
+		if name, ok := filter["name"]; ok && pkg.Name != name {
+			continue
+		}
+		pkgs = append(pkgs, *pkg)
+	}
+	return pkgs, nil
+}
+
+// GetPackage looks up a single package by name.
+func (c *CloudAPI) GetPackage(name string) (*cloudapi.Package, error) {
+	c.packages.mu.Lock()
+	defer c.packages.mu.Unlock()
+	pkg, ok := c.packages.packages[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return pkg, nil
+}
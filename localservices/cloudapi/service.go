@@ -0,0 +1,64 @@
+package cloudapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// ServiceInstance identifies the account a CloudAPI double was set up to
+// serve; checkAccount rejects any request for a different account.
+type ServiceInstance struct {
+	UserAccount string
+}
+
+// CloudAPI holds the in-memory state backing one double's HTTP handlers.
+// Each SetupHTTP call configures a single CloudAPI instance, so every store
+// a handler touches lives here rather than as a package-level global.
+type CloudAPI struct {
+	ServiceInstance *ServiceInstance
+
+	// SSH keys (see keys.go).
+	keys keyStore
+
+	// Machines and their firewall rule targeting (see machines.go).
+	machines machineStore
+
+	// Packages and networks are fixed infrastructure tests seed with
+	// AddPackage/AddNetwork (see packages.go, networks.go).
+	packages packageStore
+	networks networkStore
+
+	// Firewall rules (see fwrules.go).
+	fwrules fwRuleStore
+
+	// HTTP Signature auth, enabled via RequireHTTPSignatures (see auth.go,
+	// options.go).
+	authRequired bool
+	clockSkew    time.Duration
+
+	// Fabrics, VLANs and fabric networks (see fabrics.go).
+	fabrics fabricStore
+
+	// Machine NICs (see nics.go).
+	machineNICs nicStore
+
+	// Images (see images.go). images/imagesMu back CreateImageFromMachine,
+	// ExportImage and DeleteImage directly; imageTransitions and
+	// imageCreationPolls drive the creating->active state machine.
+	images             map[string]*cloudapi.Image
+	imagesMu           sync.Mutex
+	imageTransitions   imageTransitions
+	imageCreationPolls int
+
+	// Fault injection (see faults.go).
+	faults faultStore
+
+	// Machine snapshots, audit trail, metadata and tags (see snapshots.go,
+	// audit.go, metadata.go, tags.go).
+	snapshots snapshotStore
+	audit     auditStore
+	metadata  metadataStore
+	tags      tagStore
+}
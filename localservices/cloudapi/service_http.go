@@ -10,112 +10,164 @@ package cloudapi
 
 import (
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/joyent/gosdc/cloudapi"
+	"github.com/julienschmidt/httprouter"
 )
 
-// ErrorResponse defines a single HTTP error response.
+// ErrorResponse defines a single HTTP error response, rendered as the JSON
+// envelope CloudAPI actually returns: {"code": "...", "message": "..."}.
 type ErrorResponse struct {
-	Code        int
-	Body        string
-	contentType string
-	errorText   string
-	headers     map[string]string
-	cloudapi    *CloudAPI
+	HTTPStatus int
+	Code       string
+	Message    string
+	headers    map[string]string
+}
+
+// errorRegistry indexes every ErrorResponse this double knows how to emit by
+// its cloudapi error code, so fault injection can look one up by name
+// instead of callers constructing their own.
+var errorRegistry = map[string]*ErrorResponse{}
+
+// registerError builds an ErrorResponse and records it in errorRegistry.
+func registerError(httpStatus int, code, message string) *ErrorResponse {
+	e := &ErrorResponse{HTTPStatus: httpStatus, Code: code, Message: message}
+	errorRegistry[code] = e
+	return e
+}
+
+// LookupError returns the registered ErrorResponse for a cloudapi error
+// code, or nil if none is registered.
+func LookupError(code string) *ErrorResponse {
+	return errorRegistry[code]
 }
 
 var (
-	// ErrNotAllowed is returned when the request's method is not allowed
-	ErrNotAllowed = &ErrorResponse{
-		http.StatusMethodNotAllowed,
-		"Method is not allowed",
-		"text/plain; charset=UTF-8",
-		"MethodNotAllowedError",
-		nil,
-		nil,
-	}
+	// ErrNotAllowed is returned when the request's method is not allowed.
+	ErrNotAllowed = registerError(http.StatusMethodNotAllowed, "MethodNotAllowedError", "Method is not allowed")
 
-	// ErrNotFound is returned when the requested resource is not found
-	ErrNotFound = &ErrorResponse{
-		http.StatusNotFound,
-		"Resource Not Found",
-		"text/plain; charset=UTF-8",
-		"NotFoundError",
-		nil,
-		nil,
-	}
+	// ErrNotFound is returned when the requested resource is not found.
+	ErrNotFound = registerError(http.StatusNotFound, "NotFoundError", "Resource Not Found")
 
-	// ErrBadRequest is returned when the request is malformed or incorrect
-	ErrBadRequest = &ErrorResponse{
-		http.StatusBadRequest,
-		"Malformed request url",
-		"text/plain; charset=UTF-8",
-		"BadRequestError",
-		nil,
-		nil,
-	}
+	// ErrBadRequest is returned when the request is malformed or incorrect.
+	ErrBadRequest = registerError(http.StatusBadRequest, "BadRequestError", "Malformed request url")
+
+	// ErrInvalidArgument is returned when a request parameter is present
+	// but invalid.
+	ErrInvalidArgument = registerError(http.StatusBadRequest, "InvalidArgumentError", "Invalid argument")
+
+	// ErrResourceNotFound mirrors CloudAPI's ResourceNotFoundError, distinct
+	// from the router-level ErrNotFound, for handlers and injected faults
+	// that need the real envelope on a found route.
+	ErrResourceNotFound = registerError(http.StatusNotFound, "ResourceNotFoundError", "Resource Not Found")
+
+	// ErrServiceUnavailable is returned when the backing service is
+	// unavailable. It carries no headers itself; WithRetryAfter returns a
+	// copy with the Retry-After header set.
+	ErrServiceUnavailable = registerError(http.StatusServiceUnavailable, "ServiceUnavailableError", "Service Unavailable")
+
+	// ErrRequestThrottled is returned when the client is being rate
+	// limited. It carries no headers itself; WithResponseTime returns a copy
+	// with the x-response-time header set.
+	ErrRequestThrottled = registerError(http.StatusTooManyRequests, "RequestThrottledError", "Request Throttled")
 )
 
 func (e *ErrorResponse) Error() string {
-	return e.errorText
+	return e.Code
 }
 
-func (e *ErrorResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if e.contentType != "" {
-		w.Header().Set("Content-Type", e.contentType)
+// WithRetryAfter returns a copy of e with a Retry-After header set to the
+// given number of seconds, as CloudAPI does for ServiceUnavailableError.
+func (e *ErrorResponse) WithRetryAfter(seconds int) *ErrorResponse {
+	return e.withHeader("Retry-After", strconv.Itoa(seconds))
+}
+
+// WithResponseTime returns a copy of e with an x-response-time header set,
+// as CloudAPI does for RequestThrottledError.
+func (e *ErrorResponse) WithResponseTime(d time.Duration) *ErrorResponse {
+	return e.withHeader("x-response-time", strconv.FormatInt(d.Milliseconds(), 10))
+}
+
+func (e *ErrorResponse) withHeader(header, value string) *ErrorResponse {
+	headers := make(map[string]string, len(e.headers)+1)
+	for h, v := range e.headers {
+		headers[h] = v
 	}
-	body := e.Body
-	if e.headers != nil {
-		for h, v := range e.headers {
-			w.Header().Set(h, v)
-		}
+	headers[header] = value
+	clone := *e
+	clone.headers = headers
+	return &clone
+}
+
+func (e *ErrorResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := json.Marshal(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{e.Code, e.Message})
+
+	for h, v := range e.headers {
+		w.Header().Set(h, v)
 	}
+	w.Header().Set("Content-Type", "application/json")
 	// workaround for https://code.google.com/p/go/issues/detail?id=4454
 	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
-	if e.Code != 0 {
-		w.WriteHeader(e.Code)
-	}
-	if len(body) > 0 {
-		w.Write([]byte(body))
+	if e.HTTPStatus != 0 {
+		w.WriteHeader(e.HTTPStatus)
 	}
+	w.Write(body)
 }
 
-type cloudapiHandler struct {
-	cloudapi *CloudAPI
-	method   func(m *CloudAPI, w http.ResponseWriter, r *http.Request) error
-}
+// routeHandler is the signature every route handler implements. Returning an
+// error that also implements http.Handler (such as *ErrorResponse) lets a
+// handler pick its own response; any other error is reported as a 500.
+type routeHandler func(m *CloudAPI, w http.ResponseWriter, r *http.Request, ps httprouter.Params) error
 
-func (h *cloudapiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
-	// handle trailing slash in the path
-	if strings.HasSuffix(path, "/") && path != "/" {
-		ErrNotFound.ServeHTTP(w, r)
-		return
-	}
-	err := h.method(h.cloudapi, w, r)
-	if err == nil {
-		return
+// wrap adapts a routeHandler into an httprouter.Handle, applying any fault
+// injected for (method, route) before translating the handler's returned
+// error into the appropriate HTTP response.
+func (c *CloudAPI) wrap(method, route string, h routeHandler) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if d := c.injectedLatency(route); d > 0 {
+			time.Sleep(d)
+		}
+		if resp := c.injectedError(method, route); resp != nil {
+			resp.ServeHTTP(w, r)
+			return
+		}
+		if c.authRequired {
+			if err := c.verifyRequestSignature(r); err != nil {
+				c.serveError(w, r, err)
+				return
+			}
+		}
+		if err := h(c, w, r, ps); err != nil {
+			c.serveError(w, r, err)
+		}
 	}
-	var resp http.Handler
-	resp, _ = err.(http.Handler)
+}
+
+// serveError renders err as the response, falling back to a 500 for errors
+// that don't know how to serve themselves.
+func (c *CloudAPI) serveError(w http.ResponseWriter, r *http.Request, err error) {
+	resp, _ := err.(http.Handler)
 	if resp == nil {
-		resp = &ErrorResponse{
-			http.StatusInternalServerError,
-			`{"internalServerError":{"message":"Unkown Error",code:500}}`,
-			"application/json",
-			err.Error(),
-			nil,
-			h.cloudapi,
-		}
+		resp = &ErrorResponse{http.StatusInternalServerError, "UnknownError", err.Error(), nil}
 	}
 	resp.ServeHTTP(w, r)
 }
 
+// checkAccount rejects requests for an account other than the one this
+// double was created for.
+func (c *CloudAPI) checkAccount(ps httprouter.Params) error {
+	if ps.ByName("account") != c.ServiceInstance.UserAccount {
+		return ErrNotFound
+	}
+	return nil
+}
+
 func writeResponse(w http.ResponseWriter, code int, body []byte) {
 	// workaround for https://code.google.com/p/go/issues/detail?id=4454
 	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
@@ -145,567 +197,97 @@ func processFilter(rawQuery string) map[string]string {
 	return filters
 }
 
-func (c *CloudAPI) handler(method func(m *CloudAPI, w http.ResponseWriter, r *http.Request) error) http.Handler {
-	return &cloudapiHandler{c, method}
-}
-
-// handleKeys handles the keys HTTP API.
-func (c *CloudAPI) handleKeys(w http.ResponseWriter, r *http.Request) error {
-	prefix := fmt.Sprintf("/%s/keys/", c.ServiceInstance.UserAccount)
-	keyName := strings.TrimPrefix(r.URL.Path, prefix)
-	switch r.Method {
-	case "GET":
-		if strings.HasSuffix(r.URL.Path, "keys") {
-			// ListKeys
-			keys, err := c.ListKeys()
-			if err != nil {
-				return err
-			}
-			if keys == nil {
-				keys = []cloudapi.Key{}
-			}
-			resp := keys
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// GetKey
-		key, err := c.GetKey(keyName)
-		if err != nil {
-			return err
-		}
-		if key == nil {
-			key = &cloudapi.Key{}
-		}
-		resp := key
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "POST":
-		if strings.HasSuffix(r.URL.Path, "keys") {
-			// CreateKey
-			var (
-				name string
-				key  string
-			)
-			opts := &cloudapi.CreateKeyOpts{}
-			body, errB := ioutil.ReadAll(r.Body)
-			if errB != nil {
-				return errB
-			}
-			if len(body) > 0 {
-				if errJ := json.Unmarshal(body, opts); errJ != nil {
-					return errJ
-				}
-				name = opts.Name
-				key = opts.Key
-			}
-			k, err := c.CreateKey(name, key)
-			if err != nil {
-				return err
-			}
-			if k == nil {
-				k = &cloudapi.Key{}
-			}
-			resp := k
-			return sendJSON(http.StatusCreated, resp, w, r)
-		}
-
-		return ErrNotAllowed
-
-	case "PUT":
-		return ErrNotAllowed
-
-	case "DELETE":
-		if strings.HasSuffix(r.URL.Path, "keys") {
-			return ErrNotAllowed
-		}
-
-		// DeleteKey
-		err := c.DeleteKey(keyName)
-		if err != nil {
-			return err
-		}
-		return sendJSON(http.StatusNoContent, nil, w, r)
-	}
-
-	return fmt.Errorf("unknown request method %q for %s", r.Method, r.URL.Path)
-}
-
-// handleImages handles the images HTTP API.
-func (c *CloudAPI) handleImages(w http.ResponseWriter, r *http.Request) error {
-	prefix := fmt.Sprintf("/%s/images/", c.ServiceInstance.UserAccount)
-	imageID := strings.TrimPrefix(r.URL.Path, prefix)
-	switch r.Method {
-	case "GET":
-		if strings.HasSuffix(r.URL.Path, "images") {
-			// ListImages
-			images, err := c.ListImages(processFilter(r.URL.RawQuery))
-			if err != nil {
-				return err
-			}
-			if images == nil {
-				images = []cloudapi.Image{}
-			}
-			resp := images
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// GetImage
-		image, err := c.GetImage(imageID)
-		if err != nil {
-			return err
-		}
-		if image == nil {
-			image = &cloudapi.Image{}
-		}
-		resp := image
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "POST":
-		if strings.HasSuffix(r.URL.Path, "images") {
-			// CreateImageFromMachine
-			return ErrNotFound
-		}
-		return ErrNotAllowed
-
-	case "PUT":
-		return ErrNotAllowed
-
-	case "DELETE":
-		/*if strings.HasSuffix(r.URL.Path, "images") {
-			return ErrNotAllowed
-		} else {
-			err := c.DeleteImage(imageId)
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusNoContent, nil, w, r)
-		}*/
-		return ErrNotAllowed
-	}
-	return fmt.Errorf("unknown request method %q for %s", r.Method, r.URL.Path)
-}
-
-// handlePackages handles the packages HTTP API.
-func (c *CloudAPI) handlePackages(w http.ResponseWriter, r *http.Request) error {
-	prefix := fmt.Sprintf("/%s/packages/", c.ServiceInstance.UserAccount)
-	pkgName := strings.TrimPrefix(r.URL.Path, prefix)
-	switch r.Method {
-	case "GET":
-		if strings.HasSuffix(r.URL.Path, "packages") {
-			// ListPackages
-			pkgs, err := c.ListPackages(processFilter(r.URL.RawQuery))
-			if err != nil {
-				return err
-			}
-			if pkgs == nil {
-				pkgs = []cloudapi.Package{}
-			}
-			resp := pkgs
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// GetPackage
-		pkg, err := c.GetPackage(pkgName)
-		if err != nil {
-			return err
-		}
-		if pkg == nil {
-			pkg = &cloudapi.Package{}
-		}
-		resp := pkg
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "POST":
-		return ErrNotAllowed
-
-	case "PUT":
-		return ErrNotAllowed
-
-	case "DELETE":
-		return ErrNotAllowed
-	}
-
-	return fmt.Errorf("unknown request method %q for %s", r.Method, r.URL.Path)
-}
-
-// handleMachines handles the machine HTTP API.
-func (c *CloudAPI) handleMachines(w http.ResponseWriter, r *http.Request) error {
-	prefix := fmt.Sprintf("/%s/machines/", c.ServiceInstance.UserAccount)
-	machineID := strings.TrimPrefix(r.URL.Path, prefix)
-	switch r.Method {
-	case "GET":
-		if strings.HasSuffix(r.URL.Path, "machines") {
-			// ListMachines
-			machines, err := c.ListMachines(processFilter(r.URL.RawQuery))
-			if err != nil {
-				return err
-			}
-			if machines == nil {
-				machines = []*cloudapi.Machine{}
-			}
-			resp := machines
-			return sendJSON(http.StatusOK, resp, w, r)
-		} else if strings.HasSuffix(r.URL.Path, "fwrules") {
-			// ListMachineFirewallRules
-			machineID = strings.TrimSuffix(machineID, "/fwrules")
-			fwRules, err := c.ListMachineFirewallRules(machineID)
-			if err != nil {
-				return err
-			}
-			if fwRules == nil {
-				fwRules = []*cloudapi.FirewallRule{}
-			}
-			resp := fwRules
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// GetMachine
-		machine, err := c.GetMachine(machineID)
-		if err != nil {
-			return err
-		}
-		if machine == nil {
-			machine = &cloudapi.Machine{}
-		}
-		resp := machine
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "HEAD":
-		if strings.HasSuffix(r.URL.Path, "machines") {
-			// CountMachines
-			count, err := c.CountMachines()
-			if err != nil {
-				return err
-			}
-			resp := count
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		return ErrNotAllowed
-
-	case "POST":
-		if strings.HasSuffix(r.URL.Path, "machines") {
-			// CreateMachine
-			var (
-				name     string
-				pkg      string
-				image    string
-				networks []string
-				metadata = map[string]string{}
-				tags     = map[string]string{}
-			)
-			opts := map[string]interface{}{}
-			body, errB := ioutil.ReadAll(r.Body)
-			if errB != nil {
-				return errB
-			}
-			if len(body) > 0 {
-				if errJ := json.Unmarshal(body, &opts); errJ != nil {
-					fmt.Println(errJ)
-					return errJ
-				}
-				for k, v := range opts {
-					if v == nil {
-						continue
-					}
-
-					switch k {
-					case "name":
-						name = v.(string)
-					case "package":
-						pkg = v.(string)
-					case "image":
-						image = v.(string)
-					case "networks":
-						networks = []string{}
-						for _, n := range v.([]interface{}) {
-							networks = append(networks, n.(string))
-						}
-					default:
-						if strings.HasPrefix(k, "tag.") {
-							tags[k[4:]] = v.(string)
-							continue
-						}
-						if strings.HasPrefix(k, "metadata.") {
-							metadata[k[9:]] = v.(string)
-							continue
-						}
-					}
-				}
-			}
-			machine, err := c.CreateMachine(name, pkg, image, networks, metadata, tags)
-			if err != nil {
-				return err
-			}
-			if machine == nil {
-				machine = &cloudapi.Machine{}
-			}
-			resp := machine
-			return sendJSON(http.StatusCreated, resp, w, r)
-		} else if r.URL.Query().Get("action") == "stop" {
-			//StopMachine
-			err := c.StopMachine(machineID)
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		} else if r.URL.Query().Get("action") == "start" {
-			//StartMachine
-			err := c.StartMachine(machineID)
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		} else if r.URL.Query().Get("action") == "reboot" {
-			//RebootMachine
-			err := c.RebootMachine(machineID)
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		} else if r.URL.Query().Get("action") == "resize" {
-			//ResizeMachine
-			err := c.ResizeMachine(machineID, r.URL.Query().Get("package"))
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		} else if r.URL.Query().Get("action") == "rename" {
-			//RenameMachine
-			err := c.RenameMachine(machineID, r.URL.Query().Get("name"))
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		} else if r.URL.Query().Get("action") == "enable_firewall" {
-			//EnableFirewallMachine
-			err := c.EnableFirewallMachine(machineID)
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		} else if r.URL.Query().Get("action") == "disable_firewall" {
-			//DisableFirewallMachine
-			err := c.DisableFirewallMachine(machineID)
-			if err != nil {
-				return err
-			}
-			return sendJSON(http.StatusAccepted, nil, w, r)
-		}
-
-		return ErrNotAllowed
-
-	case "PUT":
-		return ErrNotAllowed
-
-	case "DELETE":
-		if strings.HasSuffix(r.URL.Path, "machines") {
-			return ErrNotAllowed
-		}
-
-		// DeleteMachine
-		err := c.DeleteMachine(machineID)
-		if err != nil {
-			return err
-		}
-		return sendJSON(http.StatusNoContent, nil, w, r)
+// SetupHTTP attaches all the needed handlers to provide the HTTP API. Any
+// Option passed configures the instance before routes are wired up.
+func (c *CloudAPI) SetupHTTP(mux *http.ServeMux, opts ...Option) {
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	return fmt.Errorf("unknown request method %q for %s", r.Method, r.URL.Path)
-}
-
-// handleFwRules handles the firewall rules HTTP API.
-func (c *CloudAPI) handleFwRules(w http.ResponseWriter, r *http.Request) error {
-	prefix := fmt.Sprintf("/%s/fwrules/", c.ServiceInstance.UserAccount)
-	fwRuleID := strings.TrimPrefix(r.URL.Path, prefix)
-	switch r.Method {
-	case "GET":
-		if strings.HasSuffix(r.URL.Path, "fwrules") {
-			// ListFirewallRules
-			fwRules, err := c.ListFirewallRules()
-			if err != nil {
-				return err
-			}
-			if fwRules == nil {
-				fwRules = []*cloudapi.FirewallRule{}
-			}
-			resp := fwRules
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// GetFirewallRule
-		fwRule, err := c.GetFirewallRule(fwRuleID)
-		if err != nil {
-			return err
-		}
-		if fwRule == nil {
-			fwRule = &cloudapi.FirewallRule{}
-		}
-		resp := fwRule
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "POST":
-		if strings.HasSuffix(r.URL.Path, "fwrules") {
-			// CreateFirewallRule
-			var (
-				rule    string
-				enabled bool
-			)
-			opts := &cloudapi.CreateFwRuleOpts{}
-			body, errB := ioutil.ReadAll(r.Body)
-			if errB != nil {
-				return errB
-			}
-			if len(body) > 0 {
-				if errJ := json.Unmarshal(body, opts); errJ != nil {
-					return errJ
-				}
-				rule = opts.Rule
-				enabled = opts.Enabled
-			}
-			fwRule, err := c.CreateFirewallRule(rule, enabled)
-			if err != nil {
-				return err
-			}
-			if fwRule == nil {
-				fwRule = &cloudapi.FirewallRule{}
-			}
-			resp := fwRule
-			return sendJSON(http.StatusCreated, resp, w, r)
-		} else if strings.HasSuffix(r.URL.Path, "enable") {
-			// EnableFirewallRule
-			fwRuleID = strings.TrimSuffix(fwRuleID, "/enable")
-			fwRule, err := c.EnableFirewallRule(fwRuleID)
-			if err != nil {
-				return err
-			}
-			if fwRule == nil {
-				fwRule = &cloudapi.FirewallRule{}
-			}
-			resp := fwRule
-			return sendJSON(http.StatusOK, resp, w, r)
-		} else if strings.HasSuffix(r.URL.Path, "disable") {
-			// DisableFirewallRule
-			fwRuleID = strings.TrimSuffix(fwRuleID, "/disable")
-			fwRule, err := c.DisableFirewallRule(fwRuleID)
-			if err != nil {
-				return err
-			}
-			if fwRule == nil {
-				fwRule = &cloudapi.FirewallRule{}
-			}
-			resp := fwRule
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// UpdateFirewallRule
-		var (
-			rule    string
-			enabled bool
-		)
-		opts := &cloudapi.CreateFwRuleOpts{}
-		body, errB := ioutil.ReadAll(r.Body)
-		if errB != nil {
-			return errB
-		}
-		if len(body) > 0 {
-			if errJ := json.Unmarshal(body, opts); errJ != nil {
-				return errJ
-			}
-			rule = opts.Rule
-			enabled = opts.Enabled
-		}
-		fwRule, err := c.UpdateFirewallRule(fwRuleID, rule, enabled)
-		if err != nil {
-			return err
-		}
-		if fwRule == nil {
-			fwRule = &cloudapi.FirewallRule{}
-		}
-		resp := fwRule
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "PUT":
-		return ErrNotAllowed
-
-	case "DELETE":
-		if strings.HasSuffix(r.URL.Path, "fwrules") {
-			return ErrNotAllowed
-		}
-
-		// DeleteFirewallRule
-		err := c.DeleteFirewallRule(fwRuleID)
-		if err != nil {
-			return err
-		}
-		return sendJSON(http.StatusNoContent, nil, w, r)
-
-	}
-
-	return fmt.Errorf("unknown request method %q for %s", r.Method, r.URL.Path)
-}
-
-// handleNetworks handles the networks HTTP API.
-func (c *CloudAPI) handleNetworks(w http.ResponseWriter, r *http.Request) error {
-	prefix := fmt.Sprintf("/%s/networks/", c.ServiceInstance.UserAccount)
-	networkID := strings.TrimPrefix(r.URL.Path, prefix)
-	switch r.Method {
-	case "GET":
-		if strings.HasSuffix(r.URL.Path, "networks") {
-			// ListNetworks
-			networks, err := c.ListNetworks()
-			if err != nil {
-				return err
-			}
-			if networks == nil {
-				networks = []cloudapi.Network{}
-			}
-			resp := networks
-			return sendJSON(http.StatusOK, resp, w, r)
-		}
-
-		// GetNetwork
-		network, err := c.GetNetwork(networkID)
-		if err != nil {
-			return err
-		}
-		if network == nil {
-			network = &cloudapi.Network{}
-		}
-		resp := network
-		return sendJSON(http.StatusOK, resp, w, r)
-
-	case "POST":
-		return ErrNotAllowed
-
-	case "PUT":
-		return ErrNotAllowed
-
-	case "DELETE":
-		return ErrNotAllowed
+	router := httprouter.New()
+	// The double previously treated a trailing slash as a 404 outright
+	// rather than redirecting; httprouter's redirect defaults would mask a
+	// POST's body loss on the client's follow-up GET, so keep matching
+	// strict instead.
+	router.RedirectTrailingSlash = false
+	router.RedirectFixedPath = false
+	router.NotFound = ErrNotFound
+	router.MethodNotAllowed = ErrNotAllowed
+
+	// register wires a route through wrap, which is what lets
+	// InjectError/InjectLatency key on the same (method, route) pair used
+	// here instead of a raw URL.
+	register := func(method, route string, h routeHandler) {
+		router.Handle(method, route, c.wrap(method, route, h))
 	}
 
-	return fmt.Errorf("unknown request method %q for %s", r.Method, r.URL.Path)
-}
-
-// SetupHTTP attaches all the needed handlers to provide the HTTP API.
-func (c *CloudAPI) SetupHTTP(mux *http.ServeMux) {
-	handlers := map[string]http.Handler{
-		"/":               ErrNotFound,
-		"/$user/":         ErrBadRequest,
-		"/$user/keys":     c.handler((*CloudAPI).handleKeys),
-		"/$user/images":   c.handler((*CloudAPI).handleImages),
-		"/$user/packages": c.handler((*CloudAPI).handlePackages),
-		"/$user/machines": c.handler((*CloudAPI).handleMachines),
-		//"/$user/datacenters": 	c.handler((*CloudAPI).handleDatacenters),
-		"/$user/fwrules":  c.handler((*CloudAPI).handleFwRules),
-		"/$user/networks": c.handler((*CloudAPI).handleNetworks),
-	}
-	for path, h := range handlers {
-		path = strings.Replace(path, "$user", c.ServiceInstance.UserAccount, 1)
-		if !strings.HasSuffix(path, "/") {
-			mux.Handle(path+"/", h)
-		}
-		mux.Handle(path, h)
-	}
+	register("GET", "/:account/keys", handleListKeys)
+	register("POST", "/:account/keys", handleCreateKey)
+	register("GET", "/:account/keys/:name", handleGetKey)
+	register("DELETE", "/:account/keys/:name", handleDeleteKey)
+
+	register("GET", "/:account/images", handleListImages)
+	register("POST", "/:account/images", handleCreateImageFromMachine)
+	register("GET", "/:account/images/:id", handleGetImage)
+	register("POST", "/:account/images/:id", handleImageAction)
+	register("DELETE", "/:account/images/:id", handleDeleteImage)
+
+	register("GET", "/:account/packages", handleListPackages)
+	register("GET", "/:account/packages/:name", handleGetPackage)
+
+	register("GET", "/:account/machines", handleListMachines)
+	register("HEAD", "/:account/machines", handleCountMachines)
+	register("POST", "/:account/machines", handleCreateMachine)
+	register("GET", "/:account/machines/:id", handleGetMachine)
+	register("DELETE", "/:account/machines/:id", handleDeleteMachine)
+	register("POST", "/:account/machines/:id", handleMachineAction)
+	register("GET", "/:account/machines/:id/fwrules", handleListMachineFirewallRules)
+
+	register("GET", "/:account/fwrules", handleListFirewallRules)
+	register("POST", "/:account/fwrules", handleCreateFirewallRule)
+	register("GET", "/:account/fwrules/:id", handleGetFirewallRule)
+	register("POST", "/:account/fwrules/:id", handleUpdateFirewallRule)
+	register("POST", "/:account/fwrules/:id/enable", handleEnableFirewallRule)
+	register("POST", "/:account/fwrules/:id/disable", handleDisableFirewallRule)
+	register("DELETE", "/:account/fwrules/:id", handleDeleteFirewallRule)
+
+	register("GET", "/:account/networks", handleListNetworks)
+	register("GET", "/:account/networks/:id", handleGetNetwork)
+
+	register("GET", "/:account/fabrics/default/vlans", handleListFabricVLANs)
+	register("POST", "/:account/fabrics/default/vlans", handleCreateFabricVLAN)
+	register("GET", "/:account/fabrics/default/vlans/:vlan_id", handleGetFabricVLAN)
+	register("DELETE", "/:account/fabrics/default/vlans/:vlan_id", handleDeleteFabricVLAN)
+	register("GET", "/:account/fabrics/default/vlans/:vlan_id/networks", handleListFabricNetworks)
+	register("POST", "/:account/fabrics/default/vlans/:vlan_id/networks", handleCreateFabricNetwork)
+	register("GET", "/:account/fabrics/default/vlans/:vlan_id/networks/:id", handleGetFabricNetwork)
+	register("DELETE", "/:account/fabrics/default/vlans/:vlan_id/networks/:id", handleDeleteFabricNetwork)
+
+	register("GET", "/:account/machines/:id/nics", handleListMachineNICs)
+	register("POST", "/:account/machines/:id/nics", handleCreateMachineNIC)
+	register("GET", "/:account/machines/:id/nics/:mac", handleGetMachineNIC)
+	register("DELETE", "/:account/machines/:id/nics/:mac", handleDeleteMachineNIC)
+
+	register("GET", "/:account/machines/:id/snapshots", handleListSnapshots)
+	register("POST", "/:account/machines/:id/snapshots", handleCreateSnapshot)
+	register("GET", "/:account/machines/:id/snapshots/:name", handleGetSnapshot)
+	register("POST", "/:account/machines/:id/snapshots/:name", handleStartMachineFromSnapshot)
+	register("DELETE", "/:account/machines/:id/snapshots/:name", handleDeleteSnapshot)
+
+	register("GET", "/:account/machines/:id/audit", handleMachineAudit)
+
+	register("GET", "/:account/machines/:id/metadata", handleGetMachineMetadata)
+	register("POST", "/:account/machines/:id/metadata", handleUpdateMachineMetadata)
+	register("GET", "/:account/machines/:id/metadata/:key", handleGetMachineMetadataKey)
+	register("DELETE", "/:account/machines/:id/metadata/:key", handleDeleteMachineMetadataKey)
+	register("DELETE", "/:account/machines/:id/metadata", handleDeleteMachineMetadata)
+
+	register("GET", "/:account/machines/:id/tags", handleListMachineTags)
+	register("POST", "/:account/machines/:id/tags", handleUpdateMachineTags)
+	register("PUT", "/:account/machines/:id/tags", handleReplaceMachineTags)
+	register("GET", "/:account/machines/:id/tags/:key", handleGetMachineTag)
+	register("DELETE", "/:account/machines/:id/tags/:key", handleDeleteMachineTag)
+	register("DELETE", "/:account/machines/:id/tags", handleDeleteMachineTags)
+
+	mux.Handle("/", router)
 }
@@ -0,0 +1,179 @@
+//
+// gosdc - Go library to interact with the Joyent CloudAPI
+//
+// CloudAPI double testing service - HTTP API tests
+//
+// Copyright (c) Joyent Inc.
+//
+
+package cloudapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+const testUserAccount = "gouser"
+
+// newTestServer wires a fresh CloudAPI double for testUserAccount behind an
+// httptest.Server, applying any Options given.
+func newTestServer(t *testing.T, opts ...Option) (*CloudAPI, *httptest.Server) {
+	t.Helper()
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	mux := http.NewServeMux()
+	c.SetupHTTP(mux, opts...)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func doRequest(t *testing.T, srv *httptest.Server, method, path string, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestSetupHTTPWrongAccountNotFound(t *testing.T) {
+	_, srv := newTestServer(t)
+	resp := doRequest(t, srv, "GET", "/someone-else/machines", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestSetupHTTPTrailingSlashNotRedirected(t *testing.T) {
+	_, srv := newTestServer(t)
+	resp := doRequest(t, srv, "GET", "/"+testUserAccount+"/machines/", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d (no redirect-driven 200)", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestSetupHTTPMethodNotAllowed(t *testing.T) {
+	_, srv := newTestServer(t)
+	resp := doRequest(t, srv, "PATCH", "/"+testUserAccount+"/machines", nil)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestPackagesAndNetworksRoundTrip(t *testing.T) {
+	c, srv := newTestServer(t)
+	c.AddPackage(&cloudapi.Package{Name: "Small", Memory: 1024})
+	c.AddNetwork(&cloudapi.Network{Id: "net1", Name: "external", Public: true})
+
+	resp := doRequest(t, srv, "GET", "/"+testUserAccount+"/packages/Small", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET package: got status %d", resp.StatusCode)
+	}
+	var pkg cloudapi.Package
+	if err := json.NewDecoder(resp.Body).Decode(&pkg); err != nil {
+		t.Fatalf("decode package: %v", err)
+	}
+	if pkg.Name != "Small" || pkg.Memory != 1024 {
+		t.Fatalf("got package %+v, want Name=Small Memory=1024", pkg)
+	}
+
+	resp = doRequest(t, srv, "GET", "/"+testUserAccount+"/networks/net1", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET network: got status %d", resp.StatusCode)
+	}
+	var network cloudapi.Network
+	if err := json.NewDecoder(resp.Body).Decode(&network); err != nil {
+		t.Fatalf("decode network: %v", err)
+	}
+	if network.Id != "net1" || !network.Public {
+		t.Fatalf("got network %+v, want Id=net1 Public=true", network)
+	}
+}
+
+func TestMachineAndKeyLifecycle(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	keyBody, err := json.Marshal(map[string]string{"name": "mykey", "key": newSigningFixture(t).publicKey})
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	created := doRequest(t, srv, "POST", "/"+testUserAccount+"/keys", keyBody)
+	if created.StatusCode != http.StatusCreated {
+		t.Fatalf("create key: got status %d", created.StatusCode)
+	}
+
+	resp := doRequest(t, srv, "POST", "/"+testUserAccount+"/machines",
+		[]byte(`{"name":"test-machine","package":"Small","image":"img1"}`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create machine: got status %d", resp.StatusCode)
+	}
+	var machine cloudapi.Machine
+	if err := json.NewDecoder(resp.Body).Decode(&machine); err != nil {
+		t.Fatalf("decode machine: %v", err)
+	}
+	if machine.Name != "test-machine" || machine.State != "running" {
+		t.Fatalf("got machine %+v, want Name=test-machine State=running", machine)
+	}
+
+	resp = doRequest(t, srv, "POST", "/"+testUserAccount+"/machines/"+machine.Id+"?action=stop", nil)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("stop machine: got status %d", resp.StatusCode)
+	}
+
+	resp = doRequest(t, srv, "GET", "/"+testUserAccount+"/machines/"+machine.Id, nil)
+	var refreshed cloudapi.Machine
+	if err := json.NewDecoder(resp.Body).Decode(&refreshed); err != nil {
+		t.Fatalf("decode refreshed machine: %v", err)
+	}
+	if refreshed.State != "stopped" {
+		t.Fatalf("got state %q after stop action, want stopped", refreshed.State)
+	}
+
+	resp = doRequest(t, srv, "DELETE", "/"+testUserAccount+"/machines/"+machine.Id, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete machine: got status %d", resp.StatusCode)
+	}
+
+	resp = doRequest(t, srv, "GET", "/"+testUserAccount+"/machines/"+machine.Id, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("get deleted machine: got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestFirewallRuleLifecycle(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	resp := doRequest(t, srv, "POST", "/"+testUserAccount+"/fwrules",
+		[]byte(`{"rule":"FROM any TO all vms ALLOW tcp port 22","enabled":true}`))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create fwrule: got status %d", resp.StatusCode)
+	}
+	var rule cloudapi.FirewallRule
+	if err := json.NewDecoder(resp.Body).Decode(&rule); err != nil {
+		t.Fatalf("decode fwrule: %v", err)
+	}
+	if !rule.Enabled || !strings.Contains(rule.Rule, "port 22") {
+		t.Fatalf("got rule %+v", rule)
+	}
+
+	resp = doRequest(t, srv, "POST", "/"+testUserAccount+"/fwrules/"+rule.Id+"/disable", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("disable fwrule: got status %d", resp.StatusCode)
+	}
+
+	resp = doRequest(t, srv, "DELETE", "/"+testUserAccount+"/fwrules/"+rule.Id, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete fwrule: got status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,85 @@
+package cloudapi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/joyent/gosdc/cloudapi"
+)
+
+// snapshotStore holds the snapshots taken of each machine, keyed by machine
+// id and then snapshot name.
+type snapshotStore struct {
+	mu        sync.Mutex
+	snapshots map[string]map[string]*cloudapi.Snapshot
+}
+
+// CreateSnapshot takes a new snapshot of machineID, naming it name if given
+// or generating a name otherwise.
+func (c *CloudAPI) CreateSnapshot(machineID, name string) (*cloudapi.Snapshot, error) {
+	machine, err := c.GetMachine(machineID)
+	if err != nil {
+		return nil, err
+	}
+	if machine == nil || machine.Id == "" {
+		return nil, ErrNotFound
+	}
+	if name == "" {
+		name = fmt.Sprintf("snapshot-%d", time.Now().UnixNano())
+	}
+
+	snap := &cloudapi.Snapshot{Name: name, State: "queued"}
+	c.snapshots.mu.Lock()
+	if c.snapshots.snapshots == nil {
+		c.snapshots.snapshots = map[string]map[string]*cloudapi.Snapshot{}
+	}
+	if c.snapshots.snapshots[machineID] == nil {
+		c.snapshots.snapshots[machineID] = map[string]*cloudapi.Snapshot{}
+	}
+	c.snapshots.snapshots[machineID][name] = snap
+	c.snapshots.mu.Unlock()
+
+	return snap, nil
+}
+
+// ListSnapshots returns every snapshot taken of machineID.
+func (c *CloudAPI) ListSnapshots(machineID string) ([]*cloudapi.Snapshot, error) {
+	c.snapshots.mu.Lock()
+	defer c.snapshots.mu.Unlock()
+	snaps := make([]*cloudapi.Snapshot, 0, len(c.snapshots.snapshots[machineID]))
+	for _, snap := range c.snapshots.snapshots[machineID] {
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// GetSnapshot looks up a single snapshot of machineID by name.
+func (c *CloudAPI) GetSnapshot(machineID, name string) (*cloudapi.Snapshot, error) {
+	c.snapshots.mu.Lock()
+	defer c.snapshots.mu.Unlock()
+	snap, ok := c.snapshots.snapshots[machineID][name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return snap, nil
+}
+
+// StartFromSnapshot boots machineID from one of its snapshots.
+func (c *CloudAPI) StartFromSnapshot(machineID, name string) error {
+	if _, err := c.GetSnapshot(machineID, name); err != nil {
+		return err
+	}
+	return c.StartMachine(machineID)
+}
+
+// DeleteSnapshot removes a single snapshot of machineID by name.
+func (c *CloudAPI) DeleteSnapshot(machineID, name string) error {
+	c.snapshots.mu.Lock()
+	defer c.snapshots.mu.Unlock()
+	if _, ok := c.snapshots.snapshots[machineID][name]; !ok {
+		return ErrNotFound
+	}
+	delete(c.snapshots.snapshots[machineID], name)
+	return nil
+}
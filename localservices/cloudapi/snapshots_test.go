@@ -0,0 +1,142 @@
+//
+// gosdc - Go library to interact with the Joyent CloudAPI
+//
+// CloudAPI double testing service - snapshots, audit, metadata and tags tests
+//
+// Copyright (c) Joyent Inc.
+//
+
+package cloudapi
+
+import "testing"
+
+func TestSnapshotLifecycle(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	snap, err := c.CreateSnapshot(machine.Id, "snap1")
+	if err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	if snap.Name != "snap1" || snap.State != "queued" {
+		t.Fatalf("got snapshot %+v, want Name=snap1 State=queued", snap)
+	}
+
+	if err := c.StopMachine(machine.Id); err != nil {
+		t.Fatalf("StopMachine: %v", err)
+	}
+	if err := c.StartFromSnapshot(machine.Id, "snap1"); err != nil {
+		t.Fatalf("StartFromSnapshot: %v", err)
+	}
+	got, err := c.GetMachine(machine.Id)
+	if err != nil {
+		t.Fatalf("GetMachine: %v", err)
+	}
+	if got.State != "running" {
+		t.Fatalf("got state %q after StartFromSnapshot, want running", got.State)
+	}
+
+	if err := c.DeleteSnapshot(machine.Id, "snap1"); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+	if _, err := c.GetSnapshot(machine.Id, "snap1"); err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound", err)
+	}
+}
+
+func TestStartFromUnknownSnapshotRejected(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+	if err := c.StartFromSnapshot(machine.Id, "no-such-snapshot"); err != ErrNotFound {
+		t.Fatalf("got err %v, want ErrNotFound", err)
+	}
+}
+
+func TestMachineAuditRecordsYesNoSuccess(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	c.recordMachineAudit(machine.Id, "stop", true)
+	c.recordMachineAudit(machine.Id, "start", false)
+
+	history, err := c.MachineAudit(machine.Id)
+	if err != nil {
+		t.Fatalf("MachineAudit: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(history))
+	}
+	// Most recent first.
+	if history[0].Action != "start" || history[0].Success != "no" {
+		t.Fatalf("got %+v, want Action=start Success=no", history[0])
+	}
+	if history[1].Action != "stop" || history[1].Success != "yes" {
+		t.Fatalf("got %+v, want Action=stop Success=yes", history[1])
+	}
+}
+
+func TestMachineMetadataRoundTrip(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	md, err := c.UpdateMachineMetadata(machine.Id, map[string]string{"role": "web"})
+	if err != nil {
+		t.Fatalf("UpdateMachineMetadata: %v", err)
+	}
+	if md["role"] != "web" {
+		t.Fatalf("got metadata %+v", md)
+	}
+
+	if err := c.DeleteMachineMetadata(machine.Id, "role"); err != nil {
+		t.Fatalf("DeleteMachineMetadata: %v", err)
+	}
+	md, err = c.GetMachineMetadata(machine.Id)
+	if err != nil {
+		t.Fatalf("GetMachineMetadata: %v", err)
+	}
+	if _, ok := md["role"]; ok {
+		t.Fatalf("got metadata %+v, want role deleted", md)
+	}
+}
+
+func TestMachineTagsReplaceAndDelete(t *testing.T) {
+	c := &CloudAPI{ServiceInstance: &ServiceInstance{UserAccount: testUserAccount}}
+	machine, err := c.CreateMachine(testMachineName, "Small", testImage, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	if _, err := c.UpdateMachineTags(machine.Id, map[string]string{"env": "dev", "team": "infra"}); err != nil {
+		t.Fatalf("UpdateMachineTags: %v", err)
+	}
+	tags, err := c.ReplaceMachineTags(machine.Id, map[string]string{"env": "prod"})
+	if err != nil {
+		t.Fatalf("ReplaceMachineTags: %v", err)
+	}
+	if len(tags) != 1 || tags["env"] != "prod" {
+		t.Fatalf("got tags %+v, want only env=prod after replace", tags)
+	}
+
+	if err := c.DeleteMachineTags(machine.Id, ""); err != nil {
+		t.Fatalf("DeleteMachineTags: %v", err)
+	}
+	tags, err = c.GetMachineTags(machine.Id)
+	if err != nil {
+		t.Fatalf("GetMachineTags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Fatalf("got tags %+v, want none after delete-all", tags)
+	}
+}
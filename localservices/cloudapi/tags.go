@@ -0,0 +1,77 @@
+package cloudapi
+
+import "sync"
+
+// tagStore holds the tags set on machines, keyed by machine id and then tag
+// key, mirroring metadataStore.
+type tagStore struct {
+	mu   sync.Mutex
+	tags map[string]map[string]string
+}
+
+// GetMachineTags returns the full tag map set on a machine through
+// UpdateMachineTags or ReplaceMachineTags. Tags passed to CreateMachine are
+// kept on the machine itself and are not included.
+func (c *CloudAPI) GetMachineTags(machineID string) (map[string]string, error) {
+	c.tags.mu.Lock()
+	defer c.tags.mu.Unlock()
+	tags := map[string]string{}
+	for k, v := range c.tags.tags[machineID] {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+// UpdateMachineTags merges updates into a machine's tags and returns the
+// resulting full map.
+func (c *CloudAPI) UpdateMachineTags(machineID string, updates map[string]string) (map[string]string, error) {
+	c.tags.mu.Lock()
+	defer c.tags.mu.Unlock()
+	if c.tags.tags == nil {
+		c.tags.tags = map[string]map[string]string{}
+	}
+	if c.tags.tags[machineID] == nil {
+		c.tags.tags[machineID] = map[string]string{}
+	}
+	for k, v := range updates {
+		c.tags.tags[machineID][k] = v
+	}
+
+	tags := map[string]string{}
+	for k, v := range c.tags.tags[machineID] {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+// ReplaceMachineTags replaces a machine's entire tag set.
+func (c *CloudAPI) ReplaceMachineTags(machineID string, tags map[string]string) (map[string]string, error) {
+	c.tags.mu.Lock()
+	if c.tags.tags == nil {
+		c.tags.tags = map[string]map[string]string{}
+	}
+	replacement := map[string]string{}
+	for k, v := range tags {
+		replacement[k] = v
+	}
+	c.tags.tags[machineID] = replacement
+	c.tags.mu.Unlock()
+
+	return c.GetMachineTags(machineID)
+}
+
+// DeleteMachineTags deletes a single tag, or every tag on the machine when
+// key is empty.
+func (c *CloudAPI) DeleteMachineTags(machineID, key string) error {
+	c.tags.mu.Lock()
+	defer c.tags.mu.Unlock()
+	if key == "" {
+		delete(c.tags.tags, machineID)
+		return nil
+	}
+	if _, ok := c.tags.tags[machineID][key]; !ok {
+		return ErrNotFound
+	}
+	delete(c.tags.tags[machineID], key)
+	return nil
+}